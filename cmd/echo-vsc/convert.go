@@ -0,0 +1,133 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jeromeandrewong/echo-vsc/internal/constants"
+	"github.com/jeromeandrewong/echo-vsc/internal/converter"
+	log "github.com/jeromeandrewong/echo-vsc/internal/logger"
+	"github.com/jeromeandrewong/echo-vsc/internal/theme"
+	"github.com/jeromeandrewong/echo-vsc/internal/vsc"
+	"github.com/jeromeandrewong/echo-vsc/pkg/utils"
+)
+
+func runConvert(args []string) {
+	flags := flag.NewFlagSet("convert", flag.ExitOnError)
+
+	var themeLabel, extensionID, themeType, format, output, settingsPath, pairLabel, installDir string
+	var keepAlpha, noTimestamp, dual, install bool
+	var minContrast float64
+	var discovery discoveryFlags
+	flags.StringVar(&themeLabel, "theme", "", "label of the installed VS Code theme to convert (required)")
+	flags.StringVar(&extensionID, "extension", "", "publisher.name of the extension to take --theme from, when several have a theme with that label")
+	flags.StringVar(&themeType, "type", "", "theme type, light, dark, hc or hcLight (default: detected from the theme)")
+	flags.StringVar(&format, "format", converter.FormatITerm, "output format: iterm, iterm-binary, iterm-dynamic, alacritty, kitty, wezterm, windows-terminal or ghostty")
+	flags.StringVar(&pairLabel, "pair", "", "theme of the opposite type to combine with --theme into an iTerm light/dark preset")
+	flags.BoolVar(&dual, "dual", false, "like --pair, using the sibling theme from the same extension")
+	flags.StringVar(&output, "o", "", "output file path (default: a timestamped file in ~/Downloads)")
+	flags.StringVar(&output, "output", "", "same as -o")
+	flags.BoolVar(&noTimestamp, "no-timestamp", false, "leave the timestamp out of the default file name, so regenerating overwrites it")
+	flags.StringVar(&settingsPath, "settings", "", "VS Code settings.json to read workbench.colorCustomizations from (default: the user settings)")
+	flags.Float64Var(&minContrast, "min-contrast", 0, "minimum WCAG contrast ratio against the background, e.g. 4.5, 0 to disable (default: 7 for high contrast themes, otherwise off)")
+	flags.BoolVar(&keepAlpha, "keep-alpha", false, "keep translucent colors instead of flattening them onto the background (iterm only)")
+	flags.BoolVar(&install, "install", false, "install the theme as an iTerm2 Dynamic Profile instead of writing a preset to ~/Downloads")
+	flags.StringVar(&installDir, "install-dir", "", "Dynamic Profiles folder to install into (default: ~/Library/Application Support/iTerm2/DynamicProfiles)")
+	discovery.register(flags)
+	flags.Parse(args)
+
+	if themeLabel == "" {
+		fmt.Fprintln(os.Stderr, "convert: --theme is required")
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	if _, ok := constants.DefaultFallbackColors[themeType]; themeType != "" && !ok {
+		fmt.Fprintf(os.Stderr, "convert: invalid --type %q, expected light, dark, hc or hcLight\n", themeType)
+		os.Exit(2)
+	}
+
+	isITerm := format == converter.FormatITerm || format == converter.FormatITermBinary || format == converter.FormatITermDynamic
+	if install && isFlagSet(flags, "format") && !isITerm {
+		fmt.Fprintf(os.Stderr, "convert: --install only works with iTerm, not --format %s\n", format)
+		os.Exit(2)
+	}
+
+	if install && output != "" {
+		fmt.Fprintln(os.Stderr, "convert: --install writes to the Dynamic Profiles folder, use --install-dir instead of -o")
+		os.Exit(2)
+	}
+
+	themes := getThemes(discovery)
+
+	selected, err := vsc.FindTheme(themes, themeLabel, extensionID)
+	if errors.Is(err, vsc.ErrAmbiguousTheme) {
+		log.Fatal("🚨 Failed to find theme, pick one with --extension", "error", err)
+	}
+	if err != nil {
+		log.Fatal("🚨 Failed to find theme", "error", err)
+	}
+
+	var paired theme.Theme
+	if pairLabel != "" {
+		// the pair usually comes from the same extension, so look there first
+		paired, err = vsc.FindTheme(themes, pairLabel, selected.ExtensionID)
+		if err != nil {
+			paired, err = vsc.FindTheme(themes, pairLabel, "")
+		}
+		if err != nil {
+			log.Fatal("🚨 Failed to find paired theme", "error", err)
+		}
+	} else if dual {
+		paired = findSibling(themes, selected)
+	}
+
+	options := converter.ThemeOptions{
+		Theme:          selected,
+		OutputPath:     output,
+		NoTimestamp:    noTimestamp,
+		ShouldWrite:    true,
+		Format:         format,
+		ThemeType:      themeType,
+		NoPrompt:       true,
+		KeepAlpha:      keepAlpha,
+		ColorOverrides: getColorOverrides(settingsPath, selected.Label),
+		PairedTheme:    paired,
+	}
+
+	if paired.Path != "" {
+		options.PairedColorOverrides = getColorOverrides(settingsPath, paired.Label)
+	}
+
+	if isFlagSet(flags, "min-contrast") {
+		options.MinContrast = &minContrast
+	}
+
+	if install {
+		installDynamicProfile(&options, installDir)
+	} else if output == "" {
+		options.Directory, err = utils.GetDownloadsFolder()
+		if err != nil {
+			log.Fatal("🚨 Failed to get Downloads folder", "error", err)
+		}
+	}
+
+	filePath, err := converter.GenerateTheme(options)
+	if err != nil {
+		log.Fatal("🚨 Failed to generate theme", "error", err)
+	}
+
+	printGenerated(filePath)
+}
+
+// isFlagSet reports whether a flag was passed on the command line, to tell an
+// explicit zero value apart from the default
+func isFlagSet(flags *flag.FlagSet, name string) bool {
+	set := false
+	flags.Visit(func(f *flag.Flag) {
+		set = set || f.Name == name
+	})
+	return set
+}
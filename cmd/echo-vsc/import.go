@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jeromeandrewong/echo-vsc/internal/converter"
+	log "github.com/jeromeandrewong/echo-vsc/internal/logger"
+	"github.com/jeromeandrewong/echo-vsc/pkg/utils"
+)
+
+func runImport(args []string) {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+
+	var input, name, output string
+	var noTimestamp bool
+	flags.StringVar(&input, "input", "", "path of the .itermcolors file to import (required)")
+	flags.StringVar(&name, "name", "", "name of the generated VS Code theme (default: the input file name)")
+	flags.StringVar(&output, "o", "", "output file path (default: a timestamped file in ~/Downloads)")
+	flags.StringVar(&output, "output", "", "same as -o")
+	flags.BoolVar(&noTimestamp, "no-timestamp", false, "leave the timestamp out of the default file name, so regenerating overwrites it")
+	flags.Parse(args)
+
+	if input == "" {
+		fmt.Fprintln(os.Stderr, "import: --input is required")
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	options := converter.ImportOptions{
+		Path:        input,
+		Name:        name,
+		OutputPath:  output,
+		NoTimestamp: noTimestamp,
+		ShouldWrite: true,
+	}
+
+	if output == "" {
+		var err error
+		options.Directory, err = utils.GetDownloadsFolder()
+		if err != nil {
+			log.Fatal("🚨 Failed to get Downloads folder", "error", err)
+		}
+	}
+
+	filePath, err := converter.ImportITermTheme(options)
+	if err != nil {
+		log.Fatal("🚨 Failed to import iTerm theme", "error", err)
+	}
+
+	printGenerated(filePath)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	log "github.com/jeromeandrewong/echo-vsc/internal/logger"
+)
+
+type listedTheme struct {
+	Label         string `json:"label"`
+	Path          string `json:"path"`
+	Archive       string `json:"archive,omitempty"`
+	ExtensionID   string `json:"extensionId"`
+	Version       string `json:"version"`
+	ExtensionName string `json:"extensionName"`
+	Type          string `json:"type"`
+	UITheme       string `json:"uiTheme"`
+	Editor        string `json:"editor"`
+}
+
+func runList(args []string) {
+	flags := flag.NewFlagSet("list", flag.ExitOnError)
+
+	var asJSON bool
+	var discovery discoveryFlags
+	flags.BoolVar(&asJSON, "json", false, "print themes as a JSON array")
+	discovery.register(flags)
+	flags.Parse(args)
+
+	themes := getThemes(discovery)
+
+	// workers return themes in whatever order they finish
+	sort.SliceStable(themes, func(i, j int) bool {
+		if themes[i].ExtensionID != themes[j].ExtensionID {
+			return themes[i].ExtensionID < themes[j].ExtensionID
+		}
+		return themes[i].Label < themes[j].Label
+	})
+
+	listed := make([]listedTheme, len(themes))
+	for i, t := range themes {
+		listed[i] = listedTheme{
+			Label:         t.Label,
+			Path:          t.Path,
+			Archive:       t.Archive,
+			ExtensionID:   t.ExtensionID,
+			Version:       t.ExtensionVersion,
+			ExtensionName: t.ExtensionName,
+			Type:          t.ThemeType(),
+			UITheme:       t.UITheme,
+			Editor:        t.Editor,
+		}
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(listed); err != nil {
+			log.Fatal("🚨 Failed to encode themes", "error", err)
+		}
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "LABEL\tTYPE\tEXTENSION\tEDITOR\tPATH")
+	for i, t := range listed {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", t.Label, t.Type, t.ExtensionID, t.Editor, themes[i].Location())
+	}
+	w.Flush()
+}
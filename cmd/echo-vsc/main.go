@@ -1,9 +1,11 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/jeromeandrewong/echo-vsc/internal/converter"
 	log "github.com/jeromeandrewong/echo-vsc/internal/logger"
@@ -14,7 +16,14 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-const VSC_EXTENSION_PATH = "/.vscode/extensions"
+const usage = `Usage:
+  echo-vsc [flags]         pick a theme interactively and convert it to iTerm colors
+  echo-vsc convert [flags] convert a theme without the interactive picker
+  echo-vsc import [flags]  convert an .itermcolors file into a VS Code theme
+  echo-vsc list [--json]   print the installed themes
+
+Run 'echo-vsc <command> -h' for the flags of each command.
+`
 
 type PackageData struct {
 	Contributes struct {
@@ -23,18 +32,87 @@ type PackageData struct {
 }
 
 func main() {
+	if len(os.Args) < 2 || strings.HasPrefix(os.Args[1], "-") {
+		runInteractive(os.Args[1:])
+		return
+	}
+
+	switch os.Args[1] {
+	case "convert":
+		runConvert(os.Args[2:])
+	case "import":
+		runImport(os.Args[2:])
+	case "list":
+		runList(os.Args[2:])
+	case "help":
+		runInteractive([]string{"-h"})
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+}
+
+// stringList collects a flag that can be passed multiple times
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ", ") }
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// flags shared by every command that looks up installed themes
+type discoveryFlags struct {
+	extensionsDirs stringList
+	builtinDir     string
+	vsixFiles      stringList
+}
+
+func (d *discoveryFlags) register(flags *flag.FlagSet) {
+	flags.Var(&d.extensionsDirs, "extensions-dir", "additional extensions folder to scan, can be repeated")
+	flags.Var(&d.vsixFiles, "vsix", ".vsix archive to read themes from without installing it, can be repeated")
+	flags.StringVar(&d.builtinDir, "builtin-dir", "", "VS Code's built-in extensions folder, e.g. <install>/resources/app/extensions (default: auto-detect)")
+}
+
+func getThemes(discovery discoveryFlags) []theme.Theme {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		log.Fatal("⚠️ Error getting home directory", "error", err)
 	}
 
-	vscDir := homeDir + VSC_EXTENSION_PATH
+	dirs := vsc.GetExtensionsDirs(homeDir, discovery.builtinDir, discovery.extensionsDirs)
+	for _, vsixFile := range discovery.vsixFiles {
+		dirs = append(dirs, vsc.ExtensionsDir{Editor: "VSIX", Path: vsixFile})
+	}
 
-	themes, err := vsc.GetVSCThemes(vscDir)
+	themes, err := vsc.GetAllThemes(dirs)
 	if err != nil {
 		log.Fatal("⚠️ Failed to get VSC themes", "error", err)
 	}
 
+	return themes
+}
+
+func runInteractive(args []string) {
+	flags := flag.NewFlagSet("echo-vsc", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage+"\nFlags of the interactive picker:\n")
+		flags.PrintDefaults()
+	}
+
+	var discovery discoveryFlags
+	var noTimestamp, dual, install bool
+	var installDir string
+	discovery.register(flags)
+	flags.BoolVar(&noTimestamp, "no-timestamp", false, "leave the timestamp out of the file name, so regenerating overwrites it")
+	flags.BoolVar(&dual, "dual", false, "combine the picked theme with its light/dark sibling into one iTerm preset")
+	flags.BoolVar(&install, "install", false, "install the theme as an iTerm2 Dynamic Profile instead of writing a preset to ~/Downloads")
+	flags.StringVar(&installDir, "install-dir", "", "Dynamic Profiles folder to install into (default: ~/Library/Application Support/iTerm2/DynamicProfiles)")
+	flags.Parse(args)
+
+	themes := getThemes(discovery)
+
 	p := tea.NewProgram(theme.New(themes), tea.WithAltScreen())
 
 	m, err := p.Run()
@@ -53,21 +131,92 @@ func main() {
 		var filePath string
 
 		options := converter.ThemeOptions{
-			Theme:       m.Choice,
-			Directory:   downloadsDir,
-			ShouldWrite: true,
+			Theme:          m.Choice,
+			Directory:      downloadsDir,
+			NoTimestamp:    noTimestamp,
+			ShouldWrite:    true,
+			ColorOverrides: getColorOverrides("", m.Choice.Label),
+		}
+
+		if dual {
+			options.PairedTheme = findSibling(themes, m.Choice)
+			options.PairedColorOverrides = getColorOverrides("", options.PairedTheme.Label)
 		}
+		if install {
+			installDynamicProfile(&options, installDir)
+		}
+
 		filePath, err = converter.GenerateTheme(options)
 
 		if err != nil {
 			log.Error("🚨 Failed to generate iTerm theme", "error", err)
 		} else {
-			fileURL := fmt.Sprintf("file://%s", filepath.ToSlash(filePath))
-
-			successMessage := fmt.Sprintf("🎉 Theme generated @%s", fileURL)
-			log.Info(successMessage)
+			printGenerated(filePath)
 		}
 	} else {
 		fmt.Println("😿 No theme selected, quitting echo!")
 	}
 }
+
+// getColorOverrides loads the user's workbench.colorCustomizations for a theme.
+// Problems with the settings file are only warned about, the theme can still
+// be converted without them.
+func getColorOverrides(settingsPath string, themeLabel string) map[string]interface{} {
+	if settingsPath == "" {
+		var err error
+		settingsPath, err = vsc.GetSettingsPath()
+		if err != nil {
+			log.Warn("⚠️ Could not locate VS Code settings", "error", err)
+			return nil
+		}
+	}
+
+	overrides, err := vsc.GetColorCustomizations(settingsPath, themeLabel)
+	if err != nil {
+		log.Warn("⚠️ Ignoring VS Code color customizations", "path", settingsPath, "error", err)
+		return nil
+	}
+
+	return overrides
+}
+
+func findSibling(themes []theme.Theme, selected theme.Theme) theme.Theme {
+	sibling, ok := vsc.FindSibling(themes, selected)
+	if !ok {
+		log.Fatal("🚨 No light/dark sibling found", "theme", selected.Label, "extension", selected.ExtensionID)
+	}
+
+	log.Info(fmt.Sprintf("🌗 Pairing %s with %s", selected.Label, sibling.Label))
+	return sibling
+}
+
+// installDynamicProfile points the options at iTerm's Dynamic Profiles folder.
+// The file is named after the extension and theme, so reinstalling replaces it.
+func installDynamicProfile(options *converter.ThemeOptions, installDir string) {
+	if installDir == "" {
+		var err error
+		installDir, err = utils.GetITermDynamicProfilesFolder()
+		if err != nil {
+			log.Fatal("🚨 Failed to get iTerm Dynamic Profiles folder", "error", err)
+		}
+	}
+
+	if err := os.MkdirAll(installDir, 0755); err != nil {
+		log.Fatal("🚨 Failed to create iTerm Dynamic Profiles folder", "path", installDir, "error", err)
+	}
+
+	options.Format = converter.FormatITermDynamic
+	options.Directory = installDir
+	options.NoTimestamp = true
+}
+
+func printGenerated(filePath string) {
+	if absPath, err := filepath.Abs(filePath); err == nil {
+		filePath = absPath
+	}
+
+	fileURL := fmt.Sprintf("file://%s", filepath.ToSlash(filePath))
+
+	successMessage := fmt.Sprintf("🎉 Theme generated @%s", fileURL)
+	log.Info(successMessage)
+}
@@ -1,14 +1,37 @@
 package constants
 
 import (
-	"os"
 	"path/filepath"
 )
 
-var (
-	HomeDir, _    = os.UserHomeDir()
-	ExtensionsDir = filepath.Join(HomeDir, ".vscode", "extensions")
-)
+// editors built on VS Code and the folder under the home directory holding their extensions
+var EditorExtensionDirs = []struct {
+	Editor string
+	Dir    string
+}{
+	{"VS Code", filepath.Join(".vscode", "extensions")},
+	{"VS Code Insiders", filepath.Join(".vscode-insiders", "extensions")},
+	{"VSCodium", filepath.Join(".vscode-oss", "extensions")},
+	{"Cursor", filepath.Join(".cursor", "extensions")},
+	{"Windsurf", filepath.Join(".windsurf", "extensions")},
+	{"VS Code Server", filepath.Join(".vscode-server", "extensions")},
+}
+
+// where VS Code installs keep their built-in extensions, by runtime.GOOS,
+// relative paths are under the home directory
+var BuiltinExtensionDirs = map[string][]string{
+	"linux": {
+		"/usr/share/code/resources/app/extensions",
+		"/opt/visual-studio-code/resources/app/extensions",
+		"/snap/code/current/usr/share/code/resources/app/extensions",
+		"/var/lib/flatpak/app/com.visualstudio.code/current/active/files/extra/vscode/resources/app/extensions",
+		"/usr/lib/code/extensions",
+	},
+	"darwin": {
+		"/Applications/Visual Studio Code.app/Contents/Resources/app/extensions",
+		"Applications/Visual Studio Code.app/Contents/Resources/app/extensions",
+	},
+}
 
 var AnsiColorFromVSCode = map[string][]string{
 	"Ansi 0 Color":        {"terminal.ansiBlack"},
@@ -28,13 +51,50 @@ var AnsiColorFromVSCode = map[string][]string{
 	"Ansi 8 Color":        {"terminal.ansiBrightBlack"},
 	"Ansi 9 Color":        {"terminal.ansiBrightRed"},
 	"Background Color":    {"terminal.background", "editor.background"},
-	"Bold Color":          {},
+	"Badge Color":         {"activityBarBadge.background", "badge.background"},
+	"Bold Color":          {}, // derived from the foreground
+	"Cursor Guide Color":  {"editor.lineHighlightBackground"},
 	"Cursor Color":        {"terminalCursor.foreground", "editorCursor.foreground"},
-	"Cursor Text Color":   {"terminalCursor.foreground", "editorCursor.foreground"},
+	"Cursor Text Color":   {"terminalCursor.background", "editor.background"},
 	"Foreground Color":    {"terminal.foreground", "editor.foreground"},
 	"Selected Text Color": {"terminal.background", "editor.background"},
-	"Selection Color":     {"terminal.selectionBackground", "terminal.foreground", "editor.foreground"},
+	"Selection Color":     {"terminal.selectionBackground", "editor.selectionBackground", "terminal.foreground", "editor.foreground"},
 	"Link Color":          {"textLink.foreground"},
+	"Tab Color":           {"tab.activeBackground"},
+	"Underline Color":     {"editorLink.activeForeground", "textLink.activeForeground"},
+}
+
+// iTerm booleans emitted alongside the colors, true when the theme provides
+// the color they switch on
+var ItermColorToggles = map[string]string{
+	"Use Bold Color":      "Bold Color",
+	"Use Underline Color": "Underline Color",
+	"Use Tab Color":       "Tab Color",
+}
+
+// high contrast themes often leave the selection and cursor colors unset and
+// draw them with contrastBorder/contrastActiveBorder instead, so for those the
+// mapping falls back to the borders before the foreground
+var HighContrastColorFromVSCode = map[string][]string{
+	"Cursor Color":        {"terminalCursor.foreground", "editorCursor.foreground", "contrastActiveBorder", "contrastBorder"},
+	"Selection Color":     {"terminal.selectionBackground", "editor.selectionBackground", "contrastActiveBorder", "contrastBorder", "terminal.foreground", "editor.foreground"},
+	"Selected Text Color": {"terminal.selectionForeground", "editor.selectionForeground", "terminal.background", "editor.background"},
+}
+
+// WCAG contrast ratio enforced by default for high contrast themes, AAA level
+var DefaultMinContrast = map[string]float64{
+	"hc":      7.0,
+	"hcLight": 7.0,
+}
+
+// global settings of a TextMate .tmTheme and the VS Code color they correspond to
+var VSCodeColorFromTmTheme = map[string]string{
+	"background":    "editor.background",
+	"foreground":    "editor.foreground",
+	"caret":         "editorCursor.foreground",
+	"selection":     "editor.selectionBackground",
+	"lineHighlight": "editor.lineHighlightBackground",
+	"invisibles":    "editorWhitespace.foreground",
 }
 
 var DefaultFallbackColors = map[string]map[string]string{
@@ -56,6 +116,7 @@ var DefaultFallbackColors = map[string]map[string]string{
 		"Ansi 14 Color":       "#a4ffff",
 		"Ansi 15 Color":       "#ffffff",
 		"Background Color":    "#282a36",
+		"Badge Color":         "#ff000080",
 		"Bold Color":          "#ffffff",
 		"Cursor Color":        "#f8f8f2",
 		"Cursor Guide Color":  "#b3ecff30",
@@ -65,6 +126,7 @@ var DefaultFallbackColors = map[string]map[string]string{
 		"Selected Text Color": "#ffffff",
 		"Selection Color":     "#44475a",
 		"Tab Color":           "#bf1a12ff",
+		"Underline Color":     "#8be9fd",
 	},
 	"light": {
 		"Ansi 0 Color":        "#21222c",
@@ -84,6 +146,7 @@ var DefaultFallbackColors = map[string]map[string]string{
 		"Ansi 14 Color":       "#0db9d7",
 		"Ansi 15 Color":       "#acb0d0",
 		"Background Color":    "#fafafa",
+		"Badge Color":         "#ff000080",
 		"Bold Color":          "#444b6a",
 		"Cursor Color":        "#444b6a",
 		"Cursor Guide Color":  "#b3ecff30",
@@ -93,5 +156,66 @@ var DefaultFallbackColors = map[string]map[string]string{
 		"Selected Text Color": "#fafafa",
 		"Selection Color":     "#b4d8fd",
 		"Tab Color":           "#bf1a12ff",
+		"Underline Color":     "#005fb8",
+	},
+	"hc": {
+		"Ansi 0 Color":        "#000000",
+		"Ansi 1 Color":        "#cd0000",
+		"Ansi 2 Color":        "#00cd00",
+		"Ansi 3 Color":        "#cdcd00",
+		"Ansi 4 Color":        "#0000ee",
+		"Ansi 5 Color":        "#cd00cd",
+		"Ansi 6 Color":        "#00cdcd",
+		"Ansi 7 Color":        "#e5e5e5",
+		"Ansi 8 Color":        "#7f7f7f",
+		"Ansi 9 Color":        "#ff0000",
+		"Ansi 10 Color":       "#00ff00",
+		"Ansi 11 Color":       "#ffff00",
+		"Ansi 12 Color":       "#5c5cff",
+		"Ansi 13 Color":       "#ff00ff",
+		"Ansi 14 Color":       "#00ffff",
+		"Ansi 15 Color":       "#ffffff",
+		"Background Color":    "#000000",
+		"Badge Color":         "#ff000080",
+		"Bold Color":          "#ffffff",
+		"Cursor Color":        "#f38518",
+		"Cursor Guide Color":  "#ffffff30",
+		"Cursor Text Color":   "#000000",
+		"Foreground Color":    "#ffffff",
+		"Link Color":          "#21a6ff",
+		"Selected Text Color": "#000000",
+		"Selection Color":     "#ffffff",
+		"Tab Color":           "#000000ff",
+		"Underline Color":     "#21a6ff",
+	},
+	"hcLight": {
+		"Ansi 0 Color":        "#292929",
+		"Ansi 1 Color":        "#cd3131",
+		"Ansi 2 Color":        "#136c13",
+		"Ansi 3 Color":        "#949800",
+		"Ansi 4 Color":        "#0451a5",
+		"Ansi 5 Color":        "#bc05bc",
+		"Ansi 6 Color":        "#0598bc",
+		"Ansi 7 Color":        "#555555",
+		"Ansi 8 Color":        "#666666",
+		"Ansi 9 Color":        "#cd3131",
+		"Ansi 10 Color":       "#00bc00",
+		"Ansi 11 Color":       "#b5ba00",
+		"Ansi 12 Color":       "#0451a5",
+		"Ansi 13 Color":       "#bc05bc",
+		"Ansi 14 Color":       "#0598bc",
+		"Ansi 15 Color":       "#a5a5a5",
+		"Background Color":    "#ffffff",
+		"Badge Color":         "#ff000080",
+		"Bold Color":          "#292929",
+		"Cursor Color":        "#0f4a85",
+		"Cursor Guide Color":  "#0f4a8530",
+		"Cursor Text Color":   "#ffffff",
+		"Foreground Color":    "#292929",
+		"Link Color":          "#0f4a85",
+		"Selected Text Color": "#ffffff",
+		"Selection Color":     "#0f4a85",
+		"Tab Color":           "#ffffffff",
+		"Underline Color":     "#0f4a85",
 	},
 }
@@ -0,0 +1,54 @@
+package converter
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/jeromeandrewong/echo-vsc/pkg/utils"
+)
+
+func getAlacrittyTOML(p palette) string {
+	var buffer bytes.Buffer
+
+	writeTOMLTable(&buffer, "colors.primary", [][2]string{
+		{"background", utils.RGBAToHex(p.Colors["Background Color"])},
+		{"foreground", utils.RGBAToHex(p.Colors["Foreground Color"])},
+	})
+
+	writeTOMLTable(&buffer, "colors.cursor", [][2]string{
+		{"text", utils.RGBAToHex(p.Colors["Cursor Text Color"])},
+		{"cursor", utils.RGBAToHex(p.Colors["Cursor Color"])},
+	})
+
+	writeTOMLTable(&buffer, "colors.selection", [][2]string{
+		{"text", utils.RGBAToHex(p.Colors["Selected Text Color"])},
+		{"background", utils.RGBAToHex(p.Colors["Selection Color"])},
+	})
+
+	writeTOMLTable(&buffer, "colors.normal", ansiEntries(p, 0))
+	writeTOMLTable(&buffer, "colors.bright", ansiEntries(p, 8))
+
+	return buffer.String()
+}
+
+// ansiEntries returns the eight ANSI colors starting at the given slot, named
+// black, red, green... as most terminal configs expect
+func ansiEntries(p palette, offset int) [][2]string {
+	entries := make([][2]string, len(ansiColorNames))
+	for i, name := range ansiColorNames {
+		key := fmt.Sprintf("Ansi %d Color", i+offset)
+		entries[i] = [2]string{name, utils.RGBAToHex(p.Colors[key])}
+	}
+	return entries
+}
+
+func writeTOMLTable(buffer *bytes.Buffer, table string, entries [][2]string) {
+	if buffer.Len() > 0 {
+		buffer.WriteString("\n")
+	}
+
+	fmt.Fprintf(buffer, "[%s]\n", table)
+	for _, entry := range entries {
+		fmt.Fprintf(buffer, "%s = %q\n", entry[0], entry[1])
+	}
+}
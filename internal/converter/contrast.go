@@ -0,0 +1,78 @@
+package converter
+
+import (
+	"fmt"
+
+	log "github.com/jeromeandrewong/echo-vsc/internal/logger"
+	"github.com/jeromeandrewong/echo-vsc/pkg/utils"
+)
+
+const lightnessStep = 0.01
+
+// enforceContrast nudges the lightness of colors that fall below minRatio
+// against what they are drawn on, keeping their hue and saturation.
+// ANSI black on dark backgrounds and white on light ones are left alone,
+// themes usually make those blend in on purpose.
+func enforceContrast(colors map[string]utils.RGBA, minRatio float64) {
+	background := colors["Background Color"]
+	darkBackground := !utils.IsLight(background)
+
+	pairs := [][2]string{
+		{"Foreground Color", "Background Color"},
+		{"Selected Text Color", "Selection Color"},
+	}
+	for i := 0; i < 16; i++ {
+		if (darkBackground && i == 0) || (!darkBackground && (i == 7 || i == 15)) {
+			continue
+		}
+		pairs = append(pairs, [2]string{ansiKey(i), "Background Color"})
+	}
+
+	for _, pair := range pairs {
+		color, ok := colors[pair[0]]
+		if !ok {
+			continue
+		}
+		against := colors[pair[1]]
+
+		ratio := utils.ContrastRatio(color, against)
+		if ratio >= minRatio {
+			continue
+		}
+
+		adjusted := adjustContrast(color, against, minRatio)
+		colors[pair[0]] = adjusted
+
+		log.Debug("Adjusted color contrast",
+			"colorName", pair[0],
+			"against", pair[1],
+			"from", fmt.Sprintf("%s (%.2f)", utils.RGBAToHex(color), ratio),
+			"to", fmt.Sprintf("%s (%.2f)", utils.RGBAToHex(adjusted), utils.ContrastRatio(adjusted, against)))
+	}
+}
+
+// adjustContrast moves the color's lightness away from the other color until
+// the ratio is met, trying the opposite direction if that runs out of room
+func adjustContrast(color utils.RGBA, against utils.RGBA, minRatio float64) utils.RGBA {
+	direction := lightnessStep
+	if utils.IsLight(against) {
+		direction = -lightnessStep
+	}
+
+	best := color
+	for _, step := range []float64{direction, -direction} {
+		candidate := color
+		for i := 0; i < int(1/lightnessStep); i++ {
+			candidate = utils.Lighten(candidate, step)
+			if utils.ContrastRatio(candidate, against) >= minRatio {
+				return candidate
+			}
+		}
+
+		if utils.ContrastRatio(candidate, against) > utils.ContrastRatio(best, against) {
+			best = candidate
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,73 @@
+package converter
+
+import (
+	"math"
+	"testing"
+
+	"github.com/jeromeandrewong/echo-vsc/pkg/utils"
+)
+
+func TestEnforceContrast(t *testing.T) {
+	tests := []struct {
+		name       string
+		background string
+		color      string
+		minRatio   float64
+		darker     bool
+	}{
+		{"near invisible bright black on dark", "#282c34", "#3e4452", 4.5, false},
+		{"faint bright black on light", "#fafafa", "#c0c8d8", 4.5, true},
+		{"high contrast target", "#000000", "#3a3f8f", 7, false},
+	}
+
+	for _, tt := range tests {
+		background, _ := utils.HexToRGBA(tt.background)
+		color, _ := utils.HexToRGBA(tt.color)
+		foreground, _ := utils.HexToRGBA("#abb2bf")
+		black, _ := utils.HexToRGBA("#1e2127")
+		if utils.Luminance(background) > 0.5 {
+			foreground, _ = utils.HexToRGBA("#383a42")
+		}
+
+		colors := map[string]utils.RGBA{
+			"Background Color": background,
+			"Foreground Color": foreground,
+			ansiKey(0):         black,
+			ansiKey(8):         color,
+		}
+		enforceContrast(colors, tt.minRatio)
+
+		adjusted := colors[ansiKey(8)]
+		if ratio := utils.ContrastRatio(adjusted, background); ratio < tt.minRatio {
+			t.Errorf("%s: contrast = %.2f, want at least %.2f", tt.name, ratio, tt.minRatio)
+		}
+
+		wantHue, wantSaturation, lightness := utils.RGBAToHSL(color)
+		hue, saturation, adjustedLightness := utils.RGBAToHSL(adjusted)
+		if math.Abs(hue-wantHue) > 0.5 || math.Abs(saturation-wantSaturation) > 0.01 {
+			t.Errorf("%s: hue and saturation %.1f, %.2f, want %.1f, %.2f", tt.name, hue, saturation, wantHue, wantSaturation)
+		}
+		if tt.darker != (adjustedLightness < lightness) {
+			t.Errorf("%s: lightness went from %.2f to %.2f", tt.name, lightness, adjustedLightness)
+		}
+
+		if colors["Foreground Color"] != foreground {
+			t.Errorf("%s: foreground changed although its contrast was fine", tt.name)
+		}
+	}
+}
+
+func TestEnforceContrastKeepsBlendingBlack(t *testing.T) {
+	background, _ := utils.HexToRGBA("#282c34")
+	black, _ := utils.HexToRGBA("#2c313a")
+
+	colors := map[string]utils.RGBA{
+		"Background Color": background,
+		ansiKey(0):         black,
+	}
+	enforceContrast(colors, 4.5)
+
+	if colors[ansiKey(0)] != black {
+		t.Errorf("black on a dark background changed to %s", utils.RGBAToHex(colors[ansiKey(0)]))
+	}
+}
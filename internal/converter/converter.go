@@ -1,11 +1,15 @@
 package converter
 
 import (
-	"bytes"
+	"archive/zip"
 	"encoding/json"
 	"fmt"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/jeromeandrewong/echo-vsc/internal/constants"
@@ -14,15 +18,123 @@ import (
 	"github.com/jeromeandrewong/echo-vsc/pkg/utils"
 )
 
+const (
+	FormatITerm        = "iterm"
+	FormatITermBinary  = "iterm-binary"
+	FormatITermDynamic = "iterm-dynamic"
+	FormatAlacritty    = "alacritty"
+	FormatKitty        = "kitty"
+	FormatWezTerm      = "wezterm"
+	FormatWindows      = "windows-terminal"
+	FormatGhostty      = "ghostty"
+)
+
+// formats that can carry a real alpha channel instead of a flattened color
+var alphaFormats = map[string]bool{
+	FormatITerm:        true,
+	FormatITermBinary:  true,
+	FormatITermDynamic: true,
+}
+
+// colors iTerm draws as an overlay, so their alpha is kept whenever the format allows
+var overlayColors = map[string]bool{
+	"Badge Color":        true,
+	"Cursor Guide Color": true,
+}
+
+var fileExtensions = map[string]string{
+	FormatITerm:        "itermcolors",
+	FormatITermBinary:  "itermcolors",
+	FormatITermDynamic: "json",
+	FormatAlacritty:    "toml",
+	FormatKitty:        "conf",
+	FormatWezTerm:      "toml",
+	FormatWindows:      "json",
+	FormatGhostty:      "", // ghostty theme files have no extension
+}
+
 type ThemeOptions struct {
 	Theme       theme.Theme
 	Directory   string
+	OutputPath  string // exact file to write, takes precedence over Directory
+	NoTimestamp bool   // name the file after the theme only, so regenerating overwrites it
 	ShouldWrite bool
+	Format      string
+	ThemeType   string   // "light", "dark", "hc" or "hcLight", overrides the type from the theme file
+	NoPrompt    bool     // fail instead of asking for a missing theme type
+	KeepAlpha   bool     // keep translucent colors as-is for formats that support alpha
+	MinContrast *float64 // WCAG contrast ratio to enforce against the background, 0 to disable, nil for the type's default
+
+	// VS Code colors applied on top of the theme's own, e.g. from workbench.colorCustomizations
+	ColorOverrides map[string]interface{}
+
+	// theme of the opposite type to emit alongside Theme as an iTerm light/dark
+	// dual-mode preset, e.g. GitHub Dark for GitHub Light
+	PairedTheme theme.Theme
+
+	// ColorOverrides for PairedTheme, which usually has its own "[Theme Name]" block
+	PairedColorOverrides map[string]interface{}
+}
+
+// standard ANSI color names in slot order, "Ansi N Color" and "Ansi N+8 Color"
+// are the normal and bright variants
+var ansiColorNames = []string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+// resolved terminal colors for a theme, keyed by iTerm color name
+type palette struct {
+	Name    string
+	Type    string
+	Colors  map[string]utils.RGBA
+	Toggles map[string]bool // iTerm booleans like "Use Bold Color"
+	Source  map[string]interface{}
 }
 
 type vscodeTheme struct {
-	Colors map[string]interface{} `json:"colors"`
-	Type   string                 `json:"type"`
+	Colors         map[string]interface{} `json:"colors"`
+	Type           string                 `json:"type"`
+	Include        string                 `json:"include"`
+	RawTokenColors json.RawMessage        `json:"tokenColors"`
+	TokenColors    []tokenColor           `json:"-"`
+}
+
+// single syntax highlighting rule, either from tokenColors or a tmTheme
+type tokenColor struct {
+	Name     string    `json:"name"`
+	Scope    scopeList `json:"scope"`
+	Settings struct {
+		Foreground string `json:"foreground"`
+		Background string `json:"background"`
+		FontStyle  string `json:"fontStyle"`
+	} `json:"settings"`
+}
+
+// scopes of a token color, which themes write either as an array or as a
+// single comma separated string
+type scopeList []string
+
+func (s *scopeList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*s = splitScopes(single)
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*s = list
+	return nil
+}
+
+func splitScopes(scopes string) scopeList {
+	var list scopeList
+	for _, scope := range strings.Split(scopes, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			list = append(list, scope)
+		}
+	}
+	return list
 }
 
 func GenerateTheme(options ThemeOptions) (string, error) {
@@ -34,16 +146,37 @@ func GenerateTheme(options ThemeOptions) (string, error) {
 		options.ShouldWrite = true
 	}
 
-	fileName := fmt.Sprintf("%s-%d.itermcolors", options.Theme.Label, time.Now().Unix())
-	filePath := filepath.Join(options.Directory, fileName)
+	if options.Format == "" {
+		options.Format = FormatITerm
+	}
 
-	iTermTheme, err := convertTheme(options.Theme)
+	extension, ok := fileExtensions[options.Format]
+	if !ok {
+		return "", fmt.Errorf("unsupported output format: %s", options.Format)
+	}
+
+	filePath := options.OutputPath
+	if filePath == "" {
+		fileName := presetName(options)
+		if options.Format == FormatITermDynamic {
+			fileName = profileFileName(options)
+		}
+		if !options.NoTimestamp {
+			fileName = fmt.Sprintf("%s-%d", fileName, time.Now().Unix())
+		}
+		if extension != "" {
+			fileName += "." + extension
+		}
+		filePath = filepath.Join(options.Directory, fileName)
+	}
+
+	output, err := convertTheme(options)
 	if err != nil {
 		return "", err
 	}
 
 	if options.ShouldWrite {
-		err = os.WriteFile(filePath, []byte(iTermTheme), 0644)
+		err = os.WriteFile(filePath, []byte(output), 0644)
 		if err != nil {
 			return "", err
 		}
@@ -52,68 +185,333 @@ func GenerateTheme(options ThemeOptions) (string, error) {
 	return filePath, nil
 }
 
-func convertTheme(selectedTheme theme.Theme) (string, error) {
-	vscodeTheme, err := readTheme(selectedTheme.Path)
+func convertTheme(options ThemeOptions) (string, error) {
+	p, err := resolvePalette(options)
 	if err != nil {
-		log.Error("🚨 Failed to read theme file", "path", selectedTheme.Path, "error", err)
-		return "", fmt.Errorf("error reading theme file: %v", err)
+		return "", err
+	}
+
+	if options.PairedTheme.Path != "" {
+		preset, err := getDualItermPlist(options, p)
+		if err != nil {
+			return "", err
+		}
+		return encodeItermPreset(options, preset)
+	}
+
+	switch options.Format {
+	case FormatAlacritty:
+		return getAlacrittyTOML(p), nil
+	case FormatKitty:
+		return getKittyConf(p), nil
+	case FormatWezTerm:
+		return getWezTermTOML(p), nil
+	case FormatWindows:
+		return getWindowsTerminalJSON(p)
+	case FormatGhostty:
+		return getGhosttyTheme(p), nil
+	default:
+		return encodeItermPreset(options, getItermPlist(p))
+	}
+}
+
+// presetName names the output after the theme, and its pair for light/dark presets
+func presetName(options ThemeOptions) string {
+	name := options.Theme.Label
+	if options.PairedTheme.Path != "" {
+		name += " + " + options.PairedTheme.Label
+	}
+	return name
+}
+
+// resolvePalette reads the VS Code theme and maps it onto the iTerm color keys,
+// filling in fallbacks for anything the theme doesn't define.
+func resolvePalette(options ThemeOptions) (palette, error) {
+	selectedTheme := options.Theme
+
+	vscodeTheme, err := readTheme(selectedTheme)
+	if err != nil {
+		log.Error("🚨 Failed to read theme file", "path", selectedTheme.Location(), "error", err)
+		return palette{}, fmt.Errorf("error reading theme file: %v", err)
 	}
 
 	if vscodeTheme.Colors == nil {
-		log.Error("🚨 Invalid theme format", "path", selectedTheme.Path)
-		return "", fmt.Errorf("invalid theme format: colors not found or not a map")
+		log.Error("🚨 Invalid theme format", "path", selectedTheme.Location())
+		return palette{}, fmt.Errorf("invalid theme format: colors not found or not a map")
 	}
 
-	themeType := vscodeTheme.Type
-	if themeType == "" {
-		themeType, err = theme.GetThemeType()
+	for key, value := range options.ColorOverrides {
+		vscodeTheme.Colors[key] = value
+	}
+
+	themeType, err := resolveThemeType(options, vscodeTheme)
+	if err != nil {
+		return palette{}, err
+	}
+
+	derived := deriveAnsiColors(vscodeTheme, themeType)
+	if bold, ok := deriveBoldColor(vscodeTheme, themeType); ok {
+		derived["Bold Color"] = bold
+	}
+
+	colors := make(map[string]utils.RGBA, len(constants.AnsiColorFromVSCode))
+
+	names := make([]string, 0, len(constants.AnsiColorFromVSCode))
+	for name := range constants.AnsiColorFromVSCode {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		colorHex := getItermColor(themeType, name, vscodeTheme.Colors, derived)
+		colorRGBA, err := utils.HexToRGBA(colorHex)
 		if err != nil {
-			return "", err
+			return palette{}, err
 		}
-		if themeType == "" {
-			return "", fmt.Errorf("🚨 theme type selection cancelled")
+
+		colors[name] = colorRGBA
+	}
+
+	// translucent colors like a #ffffff22 selection only look right on top of
+	// the background, which most terminals can't do themselves
+	background := colors["Background Color"]
+	background.Alpha = 1.0
+	colors["Background Color"] = background
+
+	for name, color := range colors {
+		keepAlpha := alphaFormats[options.Format] && (options.KeepAlpha || overlayColors[name])
+		if color.Alpha < 1.0 && !keepAlpha {
+			colors[name] = utils.Composite(color, background)
 		}
 	}
 
-	var itermColors []map[string]interface{}
+	toggles := make(map[string]bool, len(constants.ItermColorToggles))
+	for toggle, name := range constants.ItermColorToggles {
+		_, derivedColor := derived[name]
+		toggles[toggle] = derivedColor || hasThemeColor(vscodeTheme.Colors, constants.AnsiColorFromVSCode[name])
+	}
+	// bold text in the bright ANSI colors, as VS Code's terminal does by default
+	toggles["Use Bright Bold"] = true
 
-	for name := range constants.AnsiColorFromVSCode {
-		colorHex := getItermColor(themeType, name, vscodeTheme.Colors)
-		colorRGBA, err := utils.HexToRGBA(colorHex)
+	minContrast := constants.DefaultMinContrast[themeType]
+	if options.MinContrast != nil {
+		minContrast = *options.MinContrast
+	}
+
+	if minContrast > 0 {
+		enforceContrast(colors, minContrast)
+	}
+
+	return palette{
+		Name:    selectedTheme.Label,
+		Type:    themeType,
+		Colors:  colors,
+		Toggles: toggles,
+		Source:  vscodeTheme.Colors,
+	}, nil
+}
+
+func resolveThemeType(options ThemeOptions, themeData vscodeTheme) (string, error) {
+	themeType, err := detectThemeType(options, themeData)
+	if err != nil {
+		return "", err
+	}
+
+	normalized := theme.NormalizeType(themeType)
+	if _, ok := constants.DefaultFallbackColors[normalized]; !ok {
+		return "", fmt.Errorf("unsupported theme type: %s", themeType)
+	}
+
+	return normalized, nil
+}
+
+// detectThemeType takes the theme type, in order of preference, from the
+// caller, the theme file, the extension's uiTheme and how light the
+// background is, only asking the user when none of them tell
+func detectThemeType(options ThemeOptions, themeData vscodeTheme) (string, error) {
+	if options.ThemeType != "" {
+		return options.ThemeType, nil
+	}
+
+	if themeData.Type != "" {
+		return themeData.Type, nil
+	}
+
+	if themeType := options.Theme.ThemeType(); themeType != "" {
+		return themeType, nil
+	}
+
+	if background, ok := firstThemeColor(themeData.Colors, "terminal.background", "editor.background"); ok {
+		themeType := "dark"
+		if utils.IsLight(background) {
+			themeType = "light"
+		}
+		log.Debug("Inferred theme type from background", "themeType", themeType)
+		return themeType, nil
+	}
+
+	if options.NoPrompt {
+		return "", fmt.Errorf("theme type not set in %s and prompting is disabled", options.Theme.Location())
+	}
+
+	themeType, err := theme.GetThemeType()
+	if err != nil {
+		return "", err
+	}
+	if themeType == "" {
+		return "", fmt.Errorf("🚨 theme type selection cancelled")
+	}
+
+	return themeType, nil
+}
+
+func readTheme(selectedTheme theme.Theme) (vscodeTheme, error) {
+	fsys, themePath, closeTheme, err := openTheme(selectedTheme)
+	if err != nil {
+		return vscodeTheme{}, err
+	}
+	defer closeTheme()
+
+	return readThemeWithIncludes(fsys, themePath, make(map[string]bool))
+}
+
+// openTheme returns the filesystem holding the theme file and its slash
+// separated path in there, the inside of the archive for themes from a .vsix
+func openTheme(selectedTheme theme.Theme) (fs.FS, string, func() error, error) {
+	if selectedTheme.Archive == "" {
+		// rooted at the extension so includes can reach files in sibling folders
+		root := selectedTheme.ExtensionPath
+		if root == "" {
+			root = filepath.Dir(selectedTheme.Path)
+		}
+
+		rel, err := filepath.Rel(root, selectedTheme.Path)
 		if err != nil {
-			return "", err
+			return nil, "", nil, fmt.Errorf("error resolving path: %v", err)
 		}
+		return os.DirFS(root), filepath.ToSlash(rel), func() error { return nil }, nil
+	}
 
-		itermColors = append(itermColors, map[string]interface{}{
-			"key":   name,
-			"red":   colorRGBA.Red,
-			"green": colorRGBA.Green,
-			"blue":  colorRGBA.Blue,
-			"alpha": colorRGBA.Alpha,
-		})
+	archive, err := zip.OpenReader(selectedTheme.Archive)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("error opening %s: %v", selectedTheme.Archive, err)
 	}
-	return getThemeXML(itermColors), nil
+	return archive, selectedTheme.Path, archive.Close, nil
 }
 
-func readTheme(path string) (vscodeTheme, error) {
-	contents, err := os.ReadFile(path)
+// readThemeWithIncludes follows the theme's include chain, merging the colors
+// so that each file overrides the ones it includes
+func readThemeWithIncludes(fsys fs.FS, themePath string, visited map[string]bool) (vscodeTheme, error) {
+	themePath = path.Clean(themePath)
+	if visited[themePath] {
+		return vscodeTheme{}, fmt.Errorf("include cycle detected at %s", themePath)
+	}
+	visited[themePath] = true
+
+	contents, err := fs.ReadFile(fsys, themePath)
 	if err != nil {
 		return vscodeTheme{}, fmt.Errorf("error reading file: %v", err)
 	}
 
+	var themeData vscodeTheme
+	if isTmTheme(themePath) {
+		themeData, err = parseTmTheme(contents)
+	} else {
+		themeData, err = parseJSONTheme(fsys, themePath, contents)
+	}
+	if err != nil {
+		return vscodeTheme{}, err
+	}
+
+	if themeData.Include == "" {
+		return themeData, nil
+	}
+
+	includePath := path.Join(path.Dir(themePath), themeData.Include)
+	parent, err := readThemeWithIncludes(fsys, includePath, visited)
+	if err != nil {
+		return vscodeTheme{}, fmt.Errorf("error reading included theme %s: %v", themeData.Include, err)
+	}
+
+	if parent.Colors != nil {
+		for key, value := range themeData.Colors {
+			parent.Colors[key] = value
+		}
+		themeData.Colors = parent.Colors
+	}
+
+	if themeData.Type == "" {
+		themeData.Type = parent.Type
+	}
+
+	// later rules win, so the child's token colors go after the parent's
+	themeData.TokenColors = append(parent.TokenColors, themeData.TokenColors...)
+
+	return themeData, nil
+}
+
+func parseJSONTheme(fsys fs.FS, themePath string, contents []byte) (vscodeTheme, error) {
 	cleanContents := utils.RemoveCommentsAndTrailingCommas(contents)
 
 	var themeData vscodeTheme
-	err = json.Unmarshal(cleanContents, &themeData)
+	err := json.Unmarshal(cleanContents, &themeData)
 	if err != nil {
 		return vscodeTheme{}, fmt.Errorf("error parsing theme JSON: %v", err)
 	}
 
+	if len(themeData.RawTokenColors) == 0 {
+		return themeData, nil
+	}
+
+	// token colors only hint at the ANSI colors, so a theme whose rules can't
+	// be read still converts from its UI colors alone
+	tokenColors, err := readTokenColors(fsys, themePath, themeData.RawTokenColors)
+	if err != nil {
+		log.Warn("⚠️ Ignoring tokenColors", "path", themePath, "error", err)
+	}
+	themeData.TokenColors = tokenColors
+
 	return themeData, nil
 }
 
-func getItermColor(themeType string, name string, vscodeTheme map[string]interface{}) string {
+// readTokenColors parses tokenColors, which is either a list of rules or a
+// path to a tmTheme holding them
+func readTokenColors(fsys fs.FS, themePath string, rawTokenColors json.RawMessage) ([]tokenColor, error) {
+	var tokenColorsPath string
+	if err := json.Unmarshal(rawTokenColors, &tokenColorsPath); err == nil {
+		tmThemeContents, err := fs.ReadFile(fsys, path.Join(path.Dir(themePath), tokenColorsPath))
+		if err != nil {
+			return nil, fmt.Errorf("error reading tokenColors file: %v", err)
+		}
+
+		tmTheme, err := parseTmTheme(tmThemeContents)
+		if err != nil {
+			return nil, err
+		}
+		return tmTheme.TokenColors, nil
+	}
+
+	var tokenColors []tokenColor
+	if err := json.Unmarshal(rawTokenColors, &tokenColors); err != nil {
+		return nil, fmt.Errorf("error parsing tokenColors: %v", err)
+	}
+	return tokenColors, nil
+}
+
+func hasThemeColor(colors map[string]interface{}, keys []string) bool {
+	for _, key := range keys {
+		if _, ok := colors[key].(string); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func getItermColor(themeType string, name string, vscodeTheme map[string]interface{}, derived map[string]string) string {
 	possibleKeys := constants.AnsiColorFromVSCode[name]
+	if highContrastKeys, ok := constants.HighContrastColorFromVSCode[name]; ok && theme.IsHighContrastType(themeType) {
+		possibleKeys = highContrastKeys
+	}
 	for _, color := range possibleKeys {
 		if val, ok := vscodeTheme[color]; ok {
 			if strVal, ok := val.(string); ok {
@@ -122,6 +520,13 @@ func getItermColor(themeType string, name string, vscodeTheme map[string]interfa
 		}
 	}
 
+	if derivedColor, ok := derived[name]; ok {
+		log.Debug("Using derived color",
+			"colorName", name,
+			"derived", derivedColor)
+		return derivedColor
+	}
+
 	fallback := constants.DefaultFallbackColors[themeType][name]
 	userMessage := fmt.Sprintf("🔧 Color '%s' is missing for this %s theme, using default fallback color.", name, themeType)
 	log.Info(userMessage)
@@ -135,39 +540,42 @@ func getItermColor(themeType string, name string, vscodeTheme map[string]interfa
 	return fallback
 }
 
-func getThemeXML(colors []map[string]interface{}) string {
-	var buffer bytes.Buffer
-
-	buffer.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
-<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
-<plist version="1.0">
-<dict>
-`)
-
-	for _, color := range colors {
-		buffer.WriteString(getItermColorComponent(color))
+// encodeItermPreset writes an .itermcolors dict as an XML or binary plist, or
+// as a dynamic profile
+func encodeItermPreset(options ThemeOptions, preset map[string]interface{}) (string, error) {
+	encode := encodePlistXML
+	switch options.Format {
+	case FormatITermBinary:
+		encode = encodePlistBinary
+	case FormatITermDynamic:
+		return getITermDynamicProfile(options, preset)
 	}
 
-	buffer.WriteString(`</dict>
-</plist>
-`)
+	data, err := encode(preset)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
 
-	return buffer.String()
+// getItermPlist builds the .itermcolors dict, one color dict per iTerm key
+func getItermPlist(p palette) map[string]interface{} {
+	preset := make(map[string]interface{}, len(p.Colors)+len(p.Toggles))
+	for name, color := range p.Colors {
+		preset[name] = getItermColorComponent(color)
+	}
+	for name, enabled := range p.Toggles {
+		preset[name] = enabled
+	}
+	return preset
 }
 
-func getItermColorComponent(color map[string]interface{}) string {
-	return fmt.Sprintf(`  <key>%s</key>
-  <dict>
-    <key>Alpha Component</key>
-    <real>%f</real>
-    <key>Blue Component</key>
-    <real>%f</real>
-    <key>Color Space</key>
-    <string>sRGB</string>
-    <key>Green Component</key>
-    <real>%f</real>
-    <key>Red Component</key>
-    <real>%f</real>
-  </dict>
-`, color["key"], color["alpha"], color["blue"], color["green"], color["red"])
+func getItermColorComponent(color utils.RGBA) map[string]interface{} {
+	return map[string]interface{}{
+		"Alpha Component": color.Alpha,
+		"Blue Component":  color.Blue,
+		"Color Space":     "sRGB",
+		"Green Component": color.Green,
+		"Red Component":   color.Red,
+	}
 }
@@ -0,0 +1,221 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jeromeandrewong/echo-vsc/internal/theme"
+	"github.com/jeromeandrewong/echo-vsc/pkg/utils"
+)
+
+// hue ranges in degrees for the six chromatic ANSI colors, indexed by ANSI slot
+var ansiHueRanges = map[int][2]float64{
+	1: {345, 20},  // red, wraps around 0
+	3: {20, 70},   // yellow, orange included
+	2: {70, 165},  // green
+	6: {165, 200}, // cyan
+	4: {200, 255}, // blue
+	5: {255, 345}, // magenta
+}
+
+// token scopes and UI colors that hint at what a theme uses for an ANSI slot.
+// A hint only counts when the color's hue actually lands in that slot.
+var ansiScopeHints = map[int][]string{
+	1: {"invalid", "markup.deleted"},
+	2: {"string", "markup.inserted"},
+	3: {"constant", "markup.changed"},
+	4: {"entity.name.function", "support.function"},
+	5: {"keyword", "storage"},
+	6: {"constant", "support.type"},
+}
+
+var ansiUIHints = map[int][]string{
+	1: {"editorError.foreground", "errorForeground"},
+	3: {"editorWarning.foreground"},
+	4: {"editorInfo.foreground"},
+}
+
+const scopeHintWeight = 3
+
+// deriveAnsiColors builds a 16 color palette from the theme's syntax and UI
+// colors, for themes that don't set terminal.ansi* themselves. Slots it can't
+// find a matching color for are left out so they use the default fallbacks.
+func deriveAnsiColors(themeData vscodeTheme, themeType string) map[string]string {
+	candidates := make(map[int]map[string]float64)
+	addCandidate := func(slot int, hex string, weight float64) {
+		if candidates[slot] == nil {
+			candidates[slot] = make(map[string]float64)
+		}
+		candidates[slot][hex] += weight
+	}
+
+	var commentColor string
+	for _, rule := range themeData.TokenColors {
+		color, err := utils.HexToRGBA(rule.Settings.Foreground)
+		if err != nil {
+			continue
+		}
+		hex := utils.RGBAToHex(color)
+
+		for _, scope := range rule.Scope {
+			if commentColor == "" && scopeMatches(scope, "comment") {
+				commentColor = hex
+			}
+
+			slot, ok := ansiSlotForColor(color)
+			if !ok {
+				continue
+			}
+
+			weight := 1.0
+			for _, hint := range ansiScopeHints[slot] {
+				if scopeMatches(scope, hint) {
+					weight += scopeHintWeight
+					break
+				}
+			}
+			addCandidate(slot, hex, weight)
+		}
+	}
+
+	for slot, keys := range ansiUIHints {
+		for _, key := range keys {
+			val, ok := themeData.Colors[key].(string)
+			if !ok {
+				continue
+			}
+			color, err := utils.HexToRGBA(val)
+			if err != nil {
+				continue
+			}
+			if colorSlot, ok := ansiSlotForColor(color); ok && colorSlot == slot {
+				addCandidate(slot, utils.RGBAToHex(color), scopeHintWeight)
+			}
+		}
+	}
+
+	light := theme.IsLightType(themeType)
+	brightShift := 0.1
+	if light {
+		brightShift = -0.1
+	}
+
+	derived := make(map[string]string)
+	for slot := range ansiHueRanges {
+		hex, ok := bestCandidate(candidates[slot])
+		if !ok {
+			continue
+		}
+
+		color, _ := utils.HexToRGBA(hex)
+		derived[ansiKey(slot)] = hex
+		derived[ansiKey(slot+8)] = utils.RGBAToHex(utils.Lighten(color, brightShift))
+	}
+
+	background, hasBackground := firstThemeColor(themeData.Colors, "terminal.background", "editor.background")
+	foreground, hasForeground := firstThemeColor(themeData.Colors, "terminal.foreground", "editor.foreground")
+	if !hasBackground || !hasForeground {
+		return derived
+	}
+
+	// black and white come from the theme's own background and foreground,
+	// bright black from its comment color since that's what it's used for
+	if light {
+		derived[ansiKey(0)] = utils.RGBAToHex(foreground)
+		derived[ansiKey(7)] = utils.RGBAToHex(utils.Mix(background, foreground, 0.3))
+		derived[ansiKey(8)] = utils.RGBAToHex(utils.Mix(background, foreground, 0.5))
+		derived[ansiKey(15)] = utils.RGBAToHex(utils.Mix(background, foreground, 0.15))
+	} else {
+		derived[ansiKey(0)] = utils.RGBAToHex(utils.Mix(background, foreground, 0.15))
+		derived[ansiKey(7)] = utils.RGBAToHex(foreground)
+		derived[ansiKey(8)] = utils.RGBAToHex(utils.Mix(background, foreground, 0.45))
+		derived[ansiKey(15)] = utils.RGBAToHex(utils.Lighten(foreground, 0.1))
+	}
+
+	if commentColor != "" {
+		derived[ansiKey(8)] = commentColor
+	}
+
+	return derived
+}
+
+// deriveBoldColor makes bold text stand out from the foreground by moving it
+// further away from the background
+func deriveBoldColor(themeData vscodeTheme, themeType string) (string, bool) {
+	foreground, ok := firstThemeColor(themeData.Colors, "terminal.foreground", "editor.foreground")
+	if !ok {
+		return "", false
+	}
+
+	shift := 0.1
+	if theme.IsLightType(themeType) {
+		shift = -0.1
+	}
+
+	return utils.RGBAToHex(utils.Lighten(foreground, shift)), true
+}
+
+// ansiSlotForColor returns the ANSI slot a color's hue belongs to, ignoring
+// colors too grey, dark or light to read as a hue
+func ansiSlotForColor(color utils.RGBA) (int, bool) {
+	hue, saturation, lightness := utils.RGBAToHSL(color)
+	if saturation < 0.25 || lightness < 0.2 || lightness > 0.85 {
+		return 0, false
+	}
+
+	for slot, hueRange := range ansiHueRanges {
+		start, end := hueRange[0], hueRange[1]
+		if start > end {
+			if hue >= start || hue < end {
+				return slot, true
+			}
+		} else if hue >= start && hue < end {
+			return slot, true
+		}
+	}
+
+	return 0, false
+}
+
+// bestCandidate returns the highest weighted color, breaking ties by hex so
+// the result is stable
+func bestCandidate(candidates map[string]float64) (string, bool) {
+	best := ""
+	bestWeight := 0.0
+	for hex, weight := range candidates {
+		if weight > bestWeight || (weight == bestWeight && hex < best) {
+			best, bestWeight = hex, weight
+		}
+	}
+	return best, best != ""
+}
+
+// scopeMatches reports whether a TextMate scope selector targets the given
+// scope or one of its children, e.g. "string.quoted.double" matches "string".
+// For descendant selectors like "meta.tag string" only the last part counts.
+func scopeMatches(selector string, scope string) bool {
+	parts := strings.Fields(selector)
+	if len(parts) == 0 {
+		return false
+	}
+
+	last := parts[len(parts)-1]
+	return last == scope || strings.HasPrefix(last, scope+".")
+}
+
+func firstThemeColor(colors map[string]interface{}, keys ...string) (utils.RGBA, bool) {
+	for _, key := range keys {
+		val, ok := colors[key].(string)
+		if !ok {
+			continue
+		}
+		if color, err := utils.HexToRGBA(val); err == nil {
+			return color, true
+		}
+	}
+	return utils.RGBA{}, false
+}
+
+func ansiKey(slot int) string {
+	return fmt.Sprintf("Ansi %d Color", slot)
+}
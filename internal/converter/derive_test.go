@@ -0,0 +1,132 @@
+package converter
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jeromeandrewong/echo-vsc/pkg/utils"
+)
+
+func TestAnsiSlotForColor(t *testing.T) {
+	tests := []struct {
+		hue  float64
+		want int
+	}{
+		{0, 1},
+		{10, 1},
+		{19, 1},
+		{345, 1}, // red wraps around 0
+		{359, 1},
+		{344, 5},
+		{21, 3},
+		{69, 3},
+		{70, 2},
+		{164, 2},
+		{165, 6},
+		{200, 4},
+		{254, 4},
+		{255, 5},
+	}
+
+	for _, tt := range tests {
+		color := utils.HSLToRGBA(tt.hue, 0.7, 0.5, 1)
+		got, ok := ansiSlotForColor(color)
+		if !ok || got != tt.want {
+			t.Errorf("hue %v: slot = %d, %v, want %d", tt.hue, got, ok, tt.want)
+		}
+	}
+
+	// too grey, dark or light to read as a hue
+	for _, color := range []utils.RGBA{
+		utils.HSLToRGBA(120, 0.1, 0.5, 1),
+		utils.HSLToRGBA(120, 0.7, 0.1, 1),
+		utils.HSLToRGBA(120, 0.7, 0.95, 1),
+	} {
+		if slot, ok := ansiSlotForColor(color); ok {
+			t.Errorf("%s: slot = %d, want none", utils.RGBAToHex(color), slot)
+		}
+	}
+}
+
+func TestDeriveAnsiColors(t *testing.T) {
+	// a small One Dark like theme
+	themeJSON := `{
+		"colors": {
+			"editor.background": "#282c34",
+			"editor.foreground": "#abb2bf",
+			"editorError.foreground": "#e06c75"
+		},
+		"tokenColors": [
+			{"scope": "comment", "settings": {"foreground": "#5c6370"}},
+			{"scope": ["string", "markup.inserted"], "settings": {"foreground": "#98c379"}},
+			{"scope": "keyword", "settings": {"foreground": "#c678dd"}},
+			{"scope": "entity.name.function", "settings": {"foreground": "#61afef"}},
+			{"scope": "support.type", "settings": {"foreground": "#56b6c2"}},
+			{"scope": "constant.numeric", "settings": {"foreground": "#d19a66"}},
+			{"scope": "variable", "settings": {"foreground": "#e06c75"}},
+			{"scope": "meta.tag string", "settings": {"foreground": "#ef596f"}}
+		]
+	}`
+
+	var themeData vscodeTheme
+	if err := json.Unmarshal([]byte(themeJSON), &themeData); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(themeData.RawTokenColors, &themeData.TokenColors); err != nil {
+		t.Fatal(err)
+	}
+
+	lighten := func(hex string, amount float64) string {
+		color, _ := utils.HexToRGBA(hex)
+		return utils.RGBAToHex(utils.Lighten(color, amount))
+	}
+	mix := func(a, b string, amount float64) string {
+		from, _ := utils.HexToRGBA(a)
+		to, _ := utils.HexToRGBA(b)
+		return utils.RGBAToHex(utils.Mix(from, to, amount))
+	}
+
+	darkWant := map[string]string{
+		ansiKey(0): mix("#282c34", "#abb2bf", 0.15),
+		ansiKey(1): "#e06c75", // the error color's hint outweighs #ef596f
+		ansiKey(2): "#98c379",
+		ansiKey(3): "#d19a66",
+		ansiKey(4): "#61afef",
+		ansiKey(5): "#c678dd",
+		ansiKey(6): "#56b6c2",
+		ansiKey(7): "#abb2bf",
+		ansiKey(8): "#5c6370", // comments
+		ansiKey(9): lighten("#e06c75", 0.1),
+	}
+
+	derived := deriveAnsiColors(themeData, "dark")
+	for key, want := range darkWant {
+		if derived[key] != want {
+			t.Errorf("dark %s = %q, want %q", key, derived[key], want)
+		}
+	}
+	if len(derived) != 16 {
+		t.Errorf("derived %d colors, want 16: %v", len(derived), derived)
+	}
+
+	// light themes darken the bright variants instead
+	derived = deriveAnsiColors(themeData, "light")
+	if want := lighten("#98c379", -0.1); derived[ansiKey(10)] != want {
+		t.Errorf("light %s = %q, want %q", ansiKey(10), derived[ansiKey(10)], want)
+	}
+	if derived[ansiKey(0)] != "#abb2bf" {
+		t.Errorf("light %s = %q, want the foreground", ansiKey(0), derived[ansiKey(0)])
+	}
+}
+
+func TestDeriveAnsiColorsLeavesUnknownSlots(t *testing.T) {
+	var themeData vscodeTheme
+	if err := json.Unmarshal([]byte(`[{"scope": "string", "settings": {"foreground": "#98c379"}}]`), &themeData.TokenColors); err != nil {
+		t.Fatal(err)
+	}
+
+	derived := deriveAnsiColors(themeData, "dark")
+	if len(derived) != 2 || derived[ansiKey(2)] != "#98c379" {
+		t.Errorf("derived = %v, want only green and bright green", derived)
+	}
+}
@@ -0,0 +1,33 @@
+package converter
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/jeromeandrewong/echo-vsc/pkg/utils"
+)
+
+// ghostty config keys mapped to the iTerm color they are taken from
+var ghosttyColorKeys = [][2]string{
+	{"background", "Background Color"},
+	{"foreground", "Foreground Color"},
+	{"cursor-color", "Cursor Color"},
+	{"cursor-text", "Cursor Text Color"},
+	{"selection-background", "Selection Color"},
+	{"selection-foreground", "Selected Text Color"},
+}
+
+func getGhosttyTheme(p palette) string {
+	var buffer bytes.Buffer
+
+	for i := 0; i < 16; i++ {
+		key := fmt.Sprintf("Ansi %d Color", i)
+		fmt.Fprintf(&buffer, "palette = %d=%s\n", i, utils.RGBAToHex(p.Colors[key]))
+	}
+
+	for _, key := range ghosttyColorKeys {
+		fmt.Fprintf(&buffer, "%s = %s\n", key[0], utils.RGBAToHex(p.Colors[key[1]]))
+	}
+
+	return buffer.String()
+}
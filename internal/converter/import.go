@@ -0,0 +1,161 @@
+package converter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jeromeandrewong/echo-vsc/internal/constants"
+	"github.com/jeromeandrewong/echo-vsc/pkg/utils"
+)
+
+type ImportOptions struct {
+	Path        string
+	Name        string
+	Directory   string
+	OutputPath  string // exact file to write, takes precedence over Directory
+	NoTimestamp bool   // name the file after the theme only, so regenerating overwrites it
+	ShouldWrite bool
+}
+
+// iTerm keys in the order they claim VS Code keys, so keys shared between
+// entries of AnsiColorFromVSCode (e.g. editor.foreground) come from the right color
+var importOrder = []string{
+	"Background Color",
+	"Foreground Color",
+	"Cursor Color",
+	"Selection Color",
+	"Link Color",
+}
+
+// VS Code keys that only make sense when converting from iTerm
+var importExtraKeys = map[string][]string{
+	"Cursor Text Color":   {"terminalCursor.background"},
+	"Selected Text Color": {"terminal.selectionForeground"},
+	"Selection Color":     {"editor.selectionBackground"},
+	"Foreground Color":    {"editorLineNumber.activeForeground"},
+	"Ansi 8 Color":        {"editorLineNumber.foreground"},
+}
+
+type vscodeThemeFile struct {
+	Name   string            `json:"name"`
+	Type   string            `json:"type"`
+	Colors map[string]string `json:"colors"`
+}
+
+// ImportITermTheme converts an .itermcolors file into a VS Code color theme JSON
+func ImportITermTheme(options ImportOptions) (string, error) {
+	if options.Name == "" {
+		options.Name = strings.TrimSuffix(filepath.Base(options.Path), filepath.Ext(options.Path))
+	}
+
+	itermColors, err := readITermColors(options.Path)
+	if err != nil {
+		return "", err
+	}
+
+	themeFile := vscodeThemeFile{
+		Name:   options.Name,
+		Type:   "dark",
+		Colors: make(map[string]string),
+	}
+
+	if background, ok := itermColors["Background Color"]; ok && utils.IsLight(background) {
+		themeFile.Type = "light"
+	}
+
+	order := append([]string{}, importOrder...)
+	for i := 0; i < 16; i++ {
+		order = append(order, fmt.Sprintf("Ansi %d Color", i))
+	}
+
+	for _, name := range order {
+		color, ok := itermColors[name]
+		if !ok {
+			continue
+		}
+		for _, key := range constants.AnsiColorFromVSCode[name] {
+			if _, claimed := themeFile.Colors[key]; !claimed {
+				themeFile.Colors[key] = utils.RGBAToHex(color)
+			}
+		}
+	}
+
+	for name, keys := range importExtraKeys {
+		color, ok := itermColors[name]
+		if !ok {
+			continue
+		}
+		for _, key := range keys {
+			themeFile.Colors[key] = utils.RGBAToHex(color)
+		}
+	}
+
+	data, err := json.MarshalIndent(themeFile, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	filePath := options.OutputPath
+	if filePath == "" {
+		fileName := options.Name + "-color-theme.json"
+		if !options.NoTimestamp {
+			fileName = fmt.Sprintf("%s-%d-color-theme.json", options.Name, time.Now().Unix())
+		}
+		filePath = filepath.Join(options.Directory, fileName)
+	}
+
+	if options.ShouldWrite {
+		err = os.WriteFile(filePath, append(data, '\n'), 0644)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return filePath, nil
+}
+
+func readITermColors(path string) (map[string]utils.RGBA, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %v", err)
+	}
+	defer file.Close()
+
+	root, err := decodePlist(file)
+	if err != nil {
+		return nil, err
+	}
+
+	dict, ok := root.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid itermcolors format: root is not a dict")
+	}
+
+	colors := make(map[string]utils.RGBA)
+	for name, value := range dict {
+		component, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		red, hasRed := component["Red Component"].(float64)
+		green, hasGreen := component["Green Component"].(float64)
+		blue, hasBlue := component["Blue Component"].(float64)
+		if !hasRed || !hasGreen || !hasBlue {
+			continue
+		}
+
+		alpha, ok := component["Alpha Component"].(float64)
+		if !ok {
+			alpha = 1.0
+		}
+
+		colors[name] = utils.RGBA{Red: red, Green: green, Blue: blue, Alpha: alpha}
+	}
+
+	return colors, nil
+}
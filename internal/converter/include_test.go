@@ -0,0 +1,136 @@
+package converter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestReadThemeWithIncludes(t *testing.T) {
+	tests := []struct {
+		name       string
+		files      map[string]string
+		themePath  string
+		wantColors map[string]interface{}
+		wantType   string
+		wantErr    string
+	}{
+		{
+			name: "child overrides parent colors",
+			files: map[string]string{
+				"themes/base.json":  `{"type": "dark", "colors": {"editor.background": "#000000", "editor.foreground": "#ffffff"}}`,
+				"themes/child.json": `{"include": "./base.json", "colors": {"editor.background": "#111111"}}`,
+			},
+			themePath: "themes/child.json",
+			wantColors: map[string]interface{}{
+				"editor.background": "#111111",
+				"editor.foreground": "#ffffff",
+			},
+			wantType: "dark",
+		},
+		{
+			name: "type is inherited through the whole chain",
+			files: map[string]string{
+				"themes/a.json": `{"include": "b.json", "colors": {"a": "#aaaaaa"}}`,
+				"themes/b.json": `{"include": "c.json", "colors": {"b": "#bbbbbb"}}`,
+				"themes/c.json": `{"type": "light", "colors": {"a": "#cccccc", "c": "#cccccc"}}`,
+			},
+			themePath:  "themes/a.json",
+			wantColors: map[string]interface{}{"a": "#aaaaaa", "b": "#bbbbbb", "c": "#cccccc"},
+			wantType:   "light",
+		},
+		{
+			name: "child type wins over the parent's",
+			files: map[string]string{
+				"themes/base.json":  `{"type": "dark", "colors": {}}`,
+				"themes/child.json": `{"type": "hc", "include": "./base.json", "colors": {}}`,
+			},
+			themePath:  "themes/child.json",
+			wantColors: map[string]interface{}{},
+			wantType:   "hc",
+		},
+		{
+			name: "include in a sibling folder",
+			files: map[string]string{
+				"shared/base.json": `{"type": "dark", "colors": {"editor.background": "#222222"}}`,
+				"themes/dark.json": `{"include": "../shared/base.json", "colors": {"editor.foreground": "#eeeeee"}}`,
+			},
+			themePath: "themes/dark.json",
+			wantColors: map[string]interface{}{
+				"editor.background": "#222222",
+				"editor.foreground": "#eeeeee",
+			},
+			wantType: "dark",
+		},
+		{
+			name: "missing tokenColors file is ignored",
+			files: map[string]string{
+				"themes/a.json": `{"type": "dark", "tokenColors": "./nope.tmTheme", "colors": {"editor.background": "#000000"}}`,
+			},
+			themePath:  "themes/a.json",
+			wantColors: map[string]interface{}{"editor.background": "#000000"},
+			wantType:   "dark",
+		},
+		{
+			name: "unreadable tokenColors rules are ignored",
+			files: map[string]string{
+				"themes/a.json": `{"type": "dark", "tokenColors": [{"scope": 42}], "colors": {"editor.background": "#000000"}}`,
+			},
+			themePath:  "themes/a.json",
+			wantColors: map[string]interface{}{"editor.background": "#000000"},
+			wantType:   "dark",
+		},
+		{
+			name: "cycle",
+			files: map[string]string{
+				"themes/a.json": `{"include": "./b.json", "colors": {}}`,
+				"themes/b.json": `{"include": "./a.json", "colors": {}}`,
+			},
+			themePath: "themes/a.json",
+			wantErr:   "include cycle detected at themes/a.json",
+		},
+		{
+			name: "self include",
+			files: map[string]string{
+				"themes/a.json": `{"include": "a.json", "colors": {}}`,
+			},
+			themePath: "themes/a.json",
+			wantErr:   "include cycle detected",
+		},
+		{
+			name: "missing include",
+			files: map[string]string{
+				"themes/a.json": `{"include": "./missing.json", "colors": {}}`,
+			},
+			themePath: "themes/a.json",
+			wantErr:   "error reading included theme ./missing.json",
+		},
+	}
+
+	for _, tt := range tests {
+		fsys := make(fstest.MapFS)
+		for name, contents := range tt.files {
+			fsys[name] = &fstest.MapFile{Data: []byte(contents)}
+		}
+
+		got, err := readThemeWithIncludes(fsys, tt.themePath, make(map[string]bool))
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: error = %v, want it to contain %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(got.Colors, tt.wantColors) {
+			t.Errorf("%s: colors = %v, want %v", tt.name, got.Colors, tt.wantColors)
+		}
+		if got.Type != tt.wantType {
+			t.Errorf("%s: type = %q, want %q", tt.name, got.Type, tt.wantType)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package converter
+
+import (
+	"fmt"
+
+	"github.com/jeromeandrewong/echo-vsc/internal/theme"
+)
+
+// getDualItermPlist builds a single iTerm preset carrying both a light and a
+// dark variant of every key, so iTerm can follow the system appearance.
+// The unsuffixed keys hold the dark variant for iTerm versions without dual mode.
+func getDualItermPlist(options ThemeOptions, first palette) (map[string]interface{}, error) {
+	if options.Format != FormatITerm && options.Format != FormatITermBinary && options.Format != FormatITermDynamic {
+		return nil, fmt.Errorf("light/dark presets are only supported for iTerm, not %s", options.Format)
+	}
+
+	pairedOptions := options
+	pairedOptions.Theme = options.PairedTheme
+	pairedOptions.PairedTheme = theme.Theme{}
+	pairedOptions.ColorOverrides = options.PairedColorOverrides
+	pairedOptions.PairedColorOverrides = nil
+	pairedOptions.ThemeType = ""
+
+	second, err := resolvePalette(pairedOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	if theme.IsLightType(first.Type) == theme.IsLightType(second.Type) {
+		return nil, fmt.Errorf("light/dark presets need one light and one dark theme, got %s (%s) and %s (%s)",
+			first.Name, first.Type, second.Name, second.Type)
+	}
+
+	light, dark := first, second
+	if !theme.IsLightType(first.Type) {
+		light, dark = second, first
+	}
+
+	preset := getItermPlist(dark)
+	for name, value := range getItermPlist(light) {
+		preset[name+" (Light)"] = value
+	}
+	for name, value := range getItermPlist(dark) {
+		preset[name+" (Dark)"] = value
+	}
+	preset["Use Separate Colors for Light and Dark Mode"] = true
+
+	return preset, nil
+}
@@ -0,0 +1,65 @@
+package converter
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jeromeandrewong/echo-vsc/internal/theme"
+)
+
+// namespace for the profile GUIDs, so they don't collide with iTerm's own
+const dynamicProfileNamespace = "echo-vsc"
+
+// getITermDynamicProfile wraps a preset in an iTerm2 Dynamic Profile. iTerm
+// reloads these from its DynamicProfiles folder whenever they change, and
+// matches profiles by GUID, so regenerating a theme updates it in place.
+func getITermDynamicProfile(options ThemeOptions, preset map[string]interface{}) (string, error) {
+	profile := make(map[string]interface{}, len(preset)+2)
+	for name, value := range preset {
+		profile[name] = value
+	}
+	profile["Name"] = presetName(options)
+	profile["Guid"] = profileGUID(options)
+
+	data, err := json.MarshalIndent(map[string]interface{}{
+		"Profiles": []interface{}{profile},
+	}, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("error encoding dynamic profile: %v", err)
+	}
+
+	return string(data) + "\n", nil
+}
+
+// profileGUID derives a name based (version 5 style) UUID from the extension
+// and theme label, which stays the same across runs and machines
+func profileGUID(options ThemeOptions) string {
+	key := dynamicProfileNamespace + "/" + options.Theme.ExtensionID + "/" + options.Theme.Label
+	if options.PairedTheme.Path != "" {
+		key += "+" + options.PairedTheme.ExtensionID + "/" + options.PairedTheme.Label
+	}
+
+	sum := sha1.Sum([]byte(key))
+	sum[6] = sum[6]&0x0f | 0x50
+	sum[8] = sum[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%X-%X-%X-%X-%X", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+// profileFileName names the profile file after what its GUID is derived from,
+// so themes only share a file when they share a profile
+func profileFileName(options ThemeOptions) string {
+	name := qualifiedLabel(options.Theme)
+	if options.PairedTheme.Path != "" {
+		name += " + " + qualifiedLabel(options.PairedTheme)
+	}
+	return name
+}
+
+func qualifiedLabel(t theme.Theme) string {
+	if t.ExtensionID == "" {
+		return t.Label
+	}
+	return t.ExtensionID + " " + t.Label
+}
@@ -0,0 +1,139 @@
+package converter
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/jeromeandrewong/echo-vsc/internal/theme"
+)
+
+var uuidV5 = regexp.MustCompile(`^[0-9A-F]{8}-[0-9A-F]{4}-5[0-9A-F]{3}-[89AB][0-9A-F]{3}-[0-9A-F]{12}$`)
+
+// writeTestTheme writes a minimal VS Code theme and returns it as found by discovery
+func writeTestTheme(t *testing.T, extensionID string, label string) theme.Theme {
+	t.Helper()
+
+	dir := t.TempDir()
+	themePath := filepath.Join(dir, "theme.json")
+	contents := `{"type": "dark", "colors": {"editor.background": "#1e1e1e", "editor.foreground": "#d4d4d4"}}`
+	if err := os.WriteFile(themePath, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return theme.Theme{
+		Label:         label,
+		Path:          themePath,
+		UITheme:       "vs-dark",
+		ExtensionID:   extensionID,
+		ExtensionPath: dir,
+	}
+}
+
+func TestProfileGUID(t *testing.T) {
+	options := ThemeOptions{Theme: theme.Theme{Label: "Dark Modern", ExtensionID: "vscode.theme-defaults"}}
+
+	guid := profileGUID(options)
+	if !uuidV5.MatchString(guid) {
+		t.Errorf("profileGUID = %q, want a version 5 UUID", guid)
+	}
+	if again := profileGUID(options); again != guid {
+		t.Errorf("profileGUID changed between calls: %q then %q", guid, again)
+	}
+
+	others := []ThemeOptions{
+		{Theme: theme.Theme{Label: "Dark Modern", ExtensionID: "someone.else"}},
+		{Theme: theme.Theme{Label: "Light Modern", ExtensionID: "vscode.theme-defaults"}},
+		{
+			Theme:       options.Theme,
+			PairedTheme: theme.Theme{Label: "Light Modern", ExtensionID: "vscode.theme-defaults", Path: "light.json"},
+		},
+	}
+	for _, other := range others {
+		if profileGUID(other) == guid {
+			t.Errorf("%+v shares the GUID of %+v", other, options)
+		}
+	}
+}
+
+func TestGenerateThemeDynamicProfile(t *testing.T) {
+	installDir := t.TempDir()
+	options := ThemeOptions{
+		Theme:       writeTestTheme(t, "publisher.cool", "Cool Dark"),
+		Directory:   installDir,
+		NoTimestamp: true,
+		ShouldWrite: true,
+		Format:      FormatITermDynamic,
+		NoPrompt:    true,
+	}
+
+	first, err := GenerateTheme(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	firstContents, err := os.ReadFile(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := GenerateTheme(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secondContents, err := os.ReadFile(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first != second {
+		t.Errorf("reinstalling wrote %s, want %s", second, first)
+	}
+	if string(firstContents) != string(secondContents) {
+		t.Errorf("reinstalling changed the profile:\n%s\n%s", firstContents, secondContents)
+	}
+
+	entries, err := os.ReadDir(installDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("got %d files in the install folder, want 1", len(entries))
+	}
+
+	var profiles struct {
+		Profiles []map[string]interface{}
+	}
+	if err := json.Unmarshal(secondContents, &profiles); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, secondContents)
+	}
+	if len(profiles.Profiles) != 1 {
+		t.Fatalf("got %d profiles, want 1", len(profiles.Profiles))
+	}
+
+	profile := profiles.Profiles[0]
+	if profile["Name"] != "Cool Dark" {
+		t.Errorf("Name = %v, want %q", profile["Name"], "Cool Dark")
+	}
+	if profile["Guid"] != profileGUID(options) {
+		t.Errorf("Guid = %v, want %q", profile["Guid"], profileGUID(options))
+	}
+	if _, ok := profile["Background Color"].(map[string]interface{}); !ok {
+		t.Errorf("Background Color = %v, want a color dict", profile["Background Color"])
+	}
+
+	// a theme with the same label from another extension gets its own profile
+	options.Theme = writeTestTheme(t, "publisher.other", "Cool Dark")
+	if _, err := GenerateTheme(options); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err = os.ReadDir(installDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("got %d files after installing a same-named theme, want 2", len(entries))
+	}
+}
@@ -0,0 +1,37 @@
+package converter
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/jeromeandrewong/echo-vsc/pkg/utils"
+)
+
+// kitty config keys mapped to the iTerm color they are taken from
+var kittyColorKeys = [][2]string{
+	{"foreground", "Foreground Color"},
+	{"background", "Background Color"},
+	{"cursor", "Cursor Color"},
+	{"cursor_text_color", "Cursor Text Color"},
+	{"selection_foreground", "Selected Text Color"},
+	{"selection_background", "Selection Color"},
+	{"url_color", "Link Color"},
+}
+
+func getKittyConf(p palette) string {
+	var buffer bytes.Buffer
+
+	fmt.Fprintf(&buffer, "# %s\n\n", p.Name)
+
+	for _, key := range kittyColorKeys {
+		fmt.Fprintf(&buffer, "%s %s\n", key[0], utils.RGBAToHex(p.Colors[key[1]]))
+	}
+
+	buffer.WriteString("\n")
+	for i := 0; i < 16; i++ {
+		key := fmt.Sprintf("Ansi %d Color", i)
+		fmt.Fprintf(&buffer, "color%d %s\n", i, utils.RGBAToHex(p.Colors[key]))
+	}
+
+	return buffer.String()
+}
@@ -0,0 +1,605 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// header of binary property lists
+const bplistHeader = "bplist00"
+
+// decodePlist parses an XML or binary property list into plain Go values:
+// dict becomes map[string]interface{}, array []interface{}, real and integer
+// float64, true/false bool and everything else string
+func decodePlist(r io.Reader) (interface{}, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("error reading plist: %v", err)
+	}
+
+	if bytes.HasPrefix(data, []byte(bplistHeader)) {
+		return decodePlistBinary(data)
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("error reading plist: %v", err)
+		}
+
+		if start, ok := token.(xml.StartElement); ok && start.Name.Local == "plist" {
+			return decodePlistNext(decoder)
+		}
+	}
+}
+
+// decodePlistNext decodes the next value element, skipping whitespace and comments
+func decodePlistNext(decoder *xml.Decoder) (interface{}, error) {
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("error reading plist: %v", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			return decodePlistValue(decoder, t)
+		case xml.EndElement:
+			return nil, io.EOF
+		}
+	}
+}
+
+func decodePlistValue(decoder *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	switch start.Name.Local {
+	case "dict":
+		dict := make(map[string]interface{})
+		for {
+			keyValue, err := decodePlistNext(decoder)
+			if err == io.EOF {
+				return dict, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			key, ok := keyValue.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid plist: dict key is not a string")
+			}
+
+			value, err := decodePlistNext(decoder)
+			if err != nil {
+				return nil, fmt.Errorf("invalid plist: missing value for key %q", key)
+			}
+			dict[key] = value
+		}
+
+	case "array":
+		var array []interface{}
+		for {
+			value, err := decodePlistNext(decoder)
+			if err == io.EOF {
+				return array, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+
+	case "true", "false":
+		if err := decoder.Skip(); err != nil {
+			return nil, err
+		}
+		return start.Name.Local == "true", nil
+
+	case "real", "integer":
+		var text string
+		if err := decoder.DecodeElement(&text, &start); err != nil {
+			return nil, err
+		}
+		number, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid plist number %q: %v", text, err)
+		}
+		return number, nil
+
+	default:
+		// key, string, date and data are all kept as their text content
+		var text string
+		if err := decoder.DecodeElement(&text, &start); err != nil {
+			return nil, err
+		}
+		return text, nil
+	}
+}
+
+// decodePlistBinary parses a bplist00 property list into the same values as
+// decodePlist does for XML, dates and data included as strings
+func decodePlistBinary(data []byte) (interface{}, error) {
+	const trailerSize = 32
+	if len(data) < len(bplistHeader)+trailerSize {
+		return nil, fmt.Errorf("invalid binary plist: too short")
+	}
+
+	trailer := data[len(data)-trailerSize:]
+	d := bplistDecoder{
+		data:       data,
+		offsetSize: int(trailer[6]),
+		refSize:    int(trailer[7]),
+		visiting:   make(map[uint64]bool),
+		decoded:    make(map[uint64]interface{}),
+	}
+	count := readBigEndian(trailer[8:16])
+	top := readBigEndian(trailer[16:24])
+	tableOffset := readBigEndian(trailer[24:32])
+
+	// written so nothing can overflow: the table has to fit between its
+	// offset and the trailer
+	tableEnd := uint64(len(data) - trailerSize)
+	if d.offsetSize == 0 || d.refSize == 0 || count == 0 || tableOffset >= tableEnd ||
+		count > (tableEnd-tableOffset)/uint64(d.offsetSize) {
+		return nil, fmt.Errorf("invalid binary plist: bad trailer")
+	}
+
+	d.offsets = make([]uint64, count)
+	for i := range d.offsets {
+		start := tableOffset + uint64(i*d.offsetSize)
+		d.offsets[i] = readBigEndian(data[start : start+uint64(d.offsetSize)])
+	}
+
+	return d.object(top)
+}
+
+type bplistDecoder struct {
+	data       []byte
+	offsets    []uint64
+	offsetSize int
+	refSize    int
+	visiting   map[uint64]bool // objects being decoded, to reject reference cycles
+
+	// objects already decoded, since objects can be shared and decoding
+	// them again for every reference can take exponential time
+	decoded map[uint64]interface{}
+}
+
+func (d *bplistDecoder) object(ref uint64) (interface{}, error) {
+	if value, ok := d.decoded[ref]; ok {
+		return value, nil
+	}
+
+	value, err := d.decodeObject(ref)
+	if err != nil {
+		return nil, err
+	}
+	d.decoded[ref] = value
+	return value, nil
+}
+
+func (d *bplistDecoder) decodeObject(ref uint64) (interface{}, error) {
+	if ref >= uint64(len(d.offsets)) || d.offsets[ref] >= uint64(len(d.data)) {
+		return nil, fmt.Errorf("invalid binary plist: object %d out of range", ref)
+	}
+	if d.visiting[ref] {
+		return nil, fmt.Errorf("invalid binary plist: object %d references itself", ref)
+	}
+	d.visiting[ref] = true
+	defer delete(d.visiting, ref)
+
+	offset := d.offsets[ref]
+	marker := d.data[offset]
+	kind, info := marker>>4, int(marker&0xF)
+	offset++
+
+	switch kind {
+	case 0x0:
+		switch marker {
+		case 0x08:
+			return false, nil
+		case 0x09:
+			return true, nil
+		}
+	case 0x1:
+		raw, err := d.bytes(offset, 1<<info)
+		if err != nil {
+			return nil, err
+		}
+		return float64(readBigEndian(raw)), nil
+	case 0x2:
+		raw, err := d.bytes(offset, 1<<info)
+		if err != nil {
+			return nil, err
+		}
+		if len(raw) == 4 {
+			return float64(math.Float32frombits(uint32(readBigEndian(raw)))), nil
+		}
+		return math.Float64frombits(readBigEndian(raw)), nil
+	case 0x3:
+		raw, err := d.bytes(offset, 8)
+		if err != nil {
+			return nil, err
+		}
+		// seconds since 2001-01-01, the reference date of Apple's clocks
+		seconds := math.Float64frombits(readBigEndian(raw))
+		reference := time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+		return reference.Add(time.Duration(seconds * float64(time.Second))).Format(time.RFC3339), nil
+	}
+
+	length, offset, err := d.length(info, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	switch kind {
+	case 0x4:
+		raw, err := d.bytes(offset, length)
+		if err != nil {
+			return nil, err
+		}
+		return base64.StdEncoding.EncodeToString(raw), nil
+	case 0x5:
+		raw, err := d.bytes(offset, length)
+		if err != nil {
+			return nil, err
+		}
+		return string(raw), nil
+	case 0x6:
+		raw, err := d.bytes(offset, length*2)
+		if err != nil {
+			return nil, err
+		}
+		units := make([]uint16, length)
+		for i := range units {
+			units[i] = uint16(readBigEndian(raw[i*2 : i*2+2]))
+		}
+		return string(utf16.Decode(units)), nil
+	case 0xA:
+		refs, err := d.refs(offset, length)
+		if err != nil {
+			return nil, err
+		}
+		array := make([]interface{}, 0, length)
+		for _, ref := range refs {
+			item, err := d.object(ref)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, item)
+		}
+		return array, nil
+	case 0xD:
+		refs, err := d.refs(offset, length*2)
+		if err != nil {
+			return nil, err
+		}
+		dict := make(map[string]interface{}, length)
+		for i := 0; i < length; i++ {
+			keyValue, err := d.object(refs[i])
+			if err != nil {
+				return nil, err
+			}
+			key, ok := keyValue.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid plist: dict key is not a string")
+			}
+			value, err := d.object(refs[length+i])
+			if err != nil {
+				return nil, err
+			}
+			dict[key] = value
+		}
+		return dict, nil
+	}
+
+	return nil, fmt.Errorf("unsupported binary plist object 0x%02x", marker)
+}
+
+// length reads the length in a marker's low nibble, or the int object
+// following it for lengths of 15 and over, returning where the contents start
+func (d *bplistDecoder) length(info int, offset uint64) (int, uint64, error) {
+	if info < 0xF {
+		return info, offset, nil
+	}
+
+	header, err := d.bytes(offset, 1)
+	if err != nil {
+		return 0, 0, err
+	}
+	if header[0]>>4 != 0x1 {
+		return 0, 0, fmt.Errorf("invalid binary plist: bad length at %d", offset)
+	}
+
+	size := 1 << (header[0] & 0xF)
+	raw, err := d.bytes(offset+1, size)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	length := readBigEndian(raw)
+	if length > uint64(len(d.data)) {
+		return 0, 0, fmt.Errorf("invalid binary plist: length %d out of range", length)
+	}
+	return int(length), offset + 1 + uint64(size), nil
+}
+
+func (d *bplistDecoder) refs(offset uint64, count int) ([]uint64, error) {
+	raw, err := d.bytes(offset, count*d.refSize)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make([]uint64, count)
+	for i := range refs {
+		refs[i] = readBigEndian(raw[i*d.refSize : (i+1)*d.refSize])
+	}
+	return refs, nil
+}
+
+func (d *bplistDecoder) bytes(offset uint64, size int) ([]byte, error) {
+	if size < 0 || offset+uint64(size) > uint64(len(d.data)) {
+		return nil, fmt.Errorf("invalid binary plist: object at %d runs past the end", offset)
+	}
+	return d.data[offset : offset+uint64(size)], nil
+}
+
+// encodePlistXML writes a value in the model decodePlist returns as an XML
+// property list. Dict keys are sorted so the output is stable.
+func encodePlistXML(value interface{}) ([]byte, error) {
+	var buffer bytes.Buffer
+
+	buffer.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+`)
+
+	if err := writePlistXMLValue(&buffer, value, 0); err != nil {
+		return nil, err
+	}
+
+	buffer.WriteString("</plist>\n")
+	return buffer.Bytes(), nil
+}
+
+func writePlistXMLValue(buffer *bytes.Buffer, value interface{}, depth int) error {
+	indent := strings.Repeat("\t", depth)
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		buffer.WriteString(indent + "<dict>\n")
+		for _, key := range sortedKeys(v) {
+			buffer.WriteString(indent + "\t<key>")
+			xml.EscapeText(buffer, []byte(key))
+			buffer.WriteString("</key>\n")
+			if err := writePlistXMLValue(buffer, v[key], depth+1); err != nil {
+				return err
+			}
+		}
+		buffer.WriteString(indent + "</dict>\n")
+
+	case []interface{}:
+		buffer.WriteString(indent + "<array>\n")
+		for _, item := range v {
+			if err := writePlistXMLValue(buffer, item, depth+1); err != nil {
+				return err
+			}
+		}
+		buffer.WriteString(indent + "</array>\n")
+
+	case string:
+		buffer.WriteString(indent + "<string>")
+		xml.EscapeText(buffer, []byte(v))
+		buffer.WriteString("</string>\n")
+
+	case float64:
+		fmt.Fprintf(buffer, "%s<real>%s</real>\n", indent, strconv.FormatFloat(v, 'f', -1, 64))
+
+	case int:
+		fmt.Fprintf(buffer, "%s<integer>%d</integer>\n", indent, v)
+
+	case bool:
+		if v {
+			buffer.WriteString(indent + "<true/>\n")
+		} else {
+			buffer.WriteString(indent + "<false/>\n")
+		}
+
+	default:
+		return fmt.Errorf("unsupported plist value of type %T", value)
+	}
+
+	return nil
+}
+
+// encodePlistBinary writes a value in the model decodePlist returns as a
+// bplist00 binary property list
+func encodePlistBinary(value interface{}) ([]byte, error) {
+	// flatten the tree into a list of objects, each container holding the
+	// indexes of its children, keys before values for dicts
+	var objects []interface{}
+	var children [][]int
+
+	var flatten func(value interface{}) (int, error)
+	flatten = func(value interface{}) (int, error) {
+		index := len(objects)
+		objects = append(objects, value)
+		children = append(children, nil)
+
+		var refs []int
+		switch v := value.(type) {
+		case map[string]interface{}:
+			keys := sortedKeys(v)
+			for _, key := range keys {
+				ref, _ := flatten(key)
+				refs = append(refs, ref)
+			}
+			for _, key := range keys {
+				ref, err := flatten(v[key])
+				if err != nil {
+					return 0, err
+				}
+				refs = append(refs, ref)
+			}
+		case []interface{}:
+			for _, item := range v {
+				ref, err := flatten(item)
+				if err != nil {
+					return 0, err
+				}
+				refs = append(refs, ref)
+			}
+		case string, float64, int, bool:
+		default:
+			return 0, fmt.Errorf("unsupported plist value of type %T", value)
+		}
+
+		children[index] = refs
+		return index, nil
+	}
+
+	if _, err := flatten(value); err != nil {
+		return nil, err
+	}
+
+	refSize := byteSize(uint64(len(objects)))
+
+	var buffer bytes.Buffer
+	buffer.WriteString(bplistHeader)
+
+	offsets := make([]uint64, len(objects))
+	for i, object := range objects {
+		offsets[i] = uint64(buffer.Len())
+
+		switch v := object.(type) {
+		case map[string]interface{}:
+			writeBplistMarker(&buffer, 0xD, len(v))
+			for _, ref := range children[i] {
+				writeBigEndian(&buffer, uint64(ref), refSize)
+			}
+		case []interface{}:
+			writeBplistMarker(&buffer, 0xA, len(v))
+			for _, ref := range children[i] {
+				writeBigEndian(&buffer, uint64(ref), refSize)
+			}
+		case string:
+			writeBplistString(&buffer, v)
+		case float64:
+			buffer.WriteByte(0x23)
+			writeBigEndian(&buffer, math.Float64bits(v), 8)
+		case int:
+			writeBplistInt(&buffer, uint64(v))
+		case bool:
+			if v {
+				buffer.WriteByte(0x09)
+			} else {
+				buffer.WriteByte(0x08)
+			}
+		}
+	}
+
+	offsetTableOffset := uint64(buffer.Len())
+	offsetSize := byteSize(offsetTableOffset)
+	for _, offset := range offsets {
+		writeBigEndian(&buffer, offset, offsetSize)
+	}
+
+	// trailer: 6 unused bytes, offset and ref sizes, object count,
+	// top object index and where the offset table starts
+	buffer.Write(make([]byte, 6))
+	buffer.WriteByte(byte(offsetSize))
+	buffer.WriteByte(byte(refSize))
+	writeBigEndian(&buffer, uint64(len(objects)), 8)
+	writeBigEndian(&buffer, 0, 8)
+	writeBigEndian(&buffer, offsetTableOffset, 8)
+
+	return buffer.Bytes(), nil
+}
+
+// writeBplistMarker writes an object type nibble with its length, spilling
+// lengths of 15 and over into a following int object
+func writeBplistMarker(buffer *bytes.Buffer, kind byte, length int) {
+	if length < 15 {
+		buffer.WriteByte(kind<<4 | byte(length))
+		return
+	}
+	buffer.WriteByte(kind<<4 | 0xF)
+	writeBplistInt(buffer, uint64(length))
+}
+
+func writeBplistInt(buffer *bytes.Buffer, value uint64) {
+	size := byteSize(value)
+	exponent := map[int]byte{1: 0, 2: 1, 4: 2, 8: 3}[size]
+	buffer.WriteByte(0x10 | exponent)
+	writeBigEndian(buffer, value, size)
+}
+
+// writeBplistString writes ASCII strings as they are and anything else as UTF-16
+func writeBplistString(buffer *bytes.Buffer, value string) {
+	ascii := true
+	for i := 0; i < len(value); i++ {
+		if value[i] > 0x7F {
+			ascii = false
+			break
+		}
+	}
+
+	if ascii {
+		writeBplistMarker(buffer, 0x5, len(value))
+		buffer.WriteString(value)
+		return
+	}
+
+	units := utf16.Encode([]rune(value))
+	writeBplistMarker(buffer, 0x6, len(units))
+	for _, unit := range units {
+		writeBigEndian(buffer, uint64(unit), 2)
+	}
+}
+
+// byteSize returns how many bytes (1, 2, 4 or 8) it takes to store value
+func byteSize(value uint64) int {
+	switch {
+	case value <= math.MaxUint8:
+		return 1
+	case value <= math.MaxUint16:
+		return 2
+	case value <= math.MaxUint32:
+		return 4
+	}
+	return 8
+}
+
+func writeBigEndian(buffer *bytes.Buffer, value uint64, size int) {
+	for i := size - 1; i >= 0; i-- {
+		buffer.WriteByte(byte(value >> (8 * i)))
+	}
+}
+
+func readBigEndian(raw []byte) uint64 {
+	var value uint64
+	for _, b := range raw {
+		value = value<<8 | uint64(b)
+	}
+	return value
+}
+
+func sortedKeys(dict map[string]interface{}) []string {
+	keys := make([]string, 0, len(dict))
+	for key := range dict {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,259 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// covers every value type, escaping, non-ASCII strings and a dict and string
+// long enough to need a separate length object in binary plists
+func samplePlist() map[string]interface{} {
+	many := make(map[string]interface{})
+	for i := 0; i < 16; i++ {
+		many[fmt.Sprintf("Key %02d", i)] = float64(i) / 16
+	}
+
+	return map[string]interface{}{
+		"Ansi 0 Color": map[string]interface{}{
+			"Alpha Component": 1.0,
+			"Blue Component":  0.2235294117647059,
+			"Color Space":     "sRGB",
+		},
+		"Use Bold Color":  true,
+		"Use Bright Bold": false,
+		"Escaped":         `<tag> & "quotes"`,
+		"Ünïcode ✓":       "héllo wörld, with emoji 🎉",
+		"Long":            "a string longer than fifteen characters",
+		"Array":           []interface{}{"one", 2.5, false},
+		"Many":            many,
+	}
+}
+
+func TestPlistXMLRoundTrip(t *testing.T) {
+	want := samplePlist()
+
+	data, err := encodePlistXML(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := decodePlist(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("decodePlist: %v\n%s", err, data)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip mismatch\n got: %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestPlistBinaryRoundTrip(t *testing.T) {
+	want := samplePlist()
+
+	data, err := encodePlistBinary(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := decodePlist(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("decodePlist: %v", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip mismatch\n got: %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestEncodePlistBinary(t *testing.T) {
+	many := make(map[string]interface{})
+	for i := 0; i < 16; i++ {
+		many[fmt.Sprintf("k%02d", i)] = true
+	}
+
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{
+			name:  "non-ASCII string as UTF-16",
+			value: "é",
+			want: "62706c6973743030" + // bplist00
+				"6100e9" + // 1 UTF-16 unit
+				"08" + // offset table
+				"000000000000" + "01" + "01" +
+				"0000000000000001" + "0000000000000000" + "000000000000000b",
+		},
+		{
+			name:  "dict with more than 15 keys",
+			value: many,
+			want: "62706c6973743030" +
+				// dict marker, then its length 16 as an int object and 32 refs
+				"df1010" +
+				"0102030405060708090a0b0c0d0e0f10" +
+				"1112131415161718191a1b1c1d1e1f20" +
+				// keys k00 to k15
+				"536b3030536b3031536b3032536b3033536b3034536b3035536b3036536b3037" +
+				"536b3038536b3039536b3130536b3131536b3132536b3133536b3134536b3135" +
+				// values
+				"09090909090909090909090909090909" +
+				// offset table
+				"08" + "2b2f33373b3f43474b4f53575b5f6367" + "6b6c6d6e6f707172737475767778797a" +
+				"000000000000" + "01" + "01" +
+				"0000000000000021" + "0000000000000000" + "000000000000007b",
+		},
+	}
+
+	for _, tt := range tests {
+		data, err := encodePlistBinary(tt.value)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got := hex.EncodeToString(data); got != tt.want {
+			t.Errorf("%s:\n got: %s\nwant: %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDecodePlistBinaryFromPlistlib(t *testing.T) {
+	// python3 -c 'import plistlib; print(plistlib.dumps({"Name": "Ünïcode ✓",
+	// "Colors": [0.5, 1], "On": True, "Count": 20}, fmt=plistlib.FMT_BINARY).hex())'
+	data, err := hex.DecodeString("62706c6973743030d4010203040508090a56436f6c6f727355436f756e74544e616d65524f6ea20607233fe0000000000000100110146900dc006e00ef0063006f0064006500202713090811181e232629323436490000000000000101000000000000000b0000000000000000000000000000004a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := decodePlist(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]interface{}{
+		"Name":   "Ünïcode ✓",
+		"Colors": []interface{}{0.5, 1.0},
+		"On":     true,
+		"Count":  20.0,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestDecodePlistBinaryInvalid(t *testing.T) {
+	data, err := encodePlistBinary(samplePlist())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, size := range []int{len(bplistHeader), len(data) / 2, len(data) - 1} {
+		if _, err := decodePlist(bytes.NewReader(data[:size])); err == nil {
+			t.Errorf("decoding %d of %d bytes succeeded, want an error", size, len(data))
+		}
+	}
+
+	// hand-made files that must fail cleanly rather than panic
+	hostile := []struct {
+		name string
+		hex  string
+	}{
+		{
+			name: "offset table past the end, wrapping around when added to",
+			hex: "62706c6973743030" +
+				"000000000000" + "01" + "01" +
+				"0000000000000001" + "0000000000000000" + "ffffffffffffffff",
+		},
+		{
+			name: "offset table just before wrapping around",
+			hex: "62706c6973743030" +
+				"000000000000" + "01" + "01" +
+				"0000000000000002" + "0000000000000000" + "ffffffffffffffff",
+		},
+		{
+			name: "top object out of range",
+			hex: "62706c6973743030" + "09" + "08" +
+				"000000000000" + "01" + "01" +
+				"0000000000000001" + "0000000000000005" + "0000000000000009",
+		},
+		{
+			name: "object offset past the end",
+			hex: "62706c6973743030" + "09" + "ff" +
+				"000000000000" + "01" + "01" +
+				"0000000000000001" + "0000000000000000" + "0000000000000009",
+		},
+		{
+			name: "array containing itself",
+			hex: "62706c6973743030" + "a100" + "08" +
+				"000000000000" + "01" + "01" +
+				"0000000000000001" + "0000000000000000" + "000000000000000a",
+		},
+		{
+			name: "string longer than the file",
+			hex: "62706c6973743030" + "5f13ffffffff" + "08" +
+				"000000000000" + "01" + "01" +
+				"0000000000000001" + "0000000000000000" + "000000000000000e",
+		},
+	}
+
+	for _, tt := range hostile {
+		data, err := hex.DecodeString(tt.hex)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Errorf("%s: panicked: %v", tt.name, r)
+				}
+			}()
+
+			if _, err := decodePlist(bytes.NewReader(data)); err == nil {
+				t.Errorf("%s: decoding succeeded, want an error", tt.name)
+			}
+		}()
+	}
+}
+
+func TestDecodePlistBinarySharedObjects(t *testing.T) {
+	// 60 nested arrays each referencing the next one twice, 2^60 values if
+	// shared objects were decoded once per reference
+	const depth = 60
+
+	data := []byte(bplistHeader)
+	var offsets []byte
+	for i := 0; i < depth; i++ {
+		offsets = append(offsets, byte(len(data)))
+		data = append(data, 0xA2, byte(i+1), byte(i+1))
+	}
+	offsets = append(offsets, byte(len(data)))
+	data = append(data, 0x09)
+
+	tableOffset := len(data)
+	data = append(data, offsets...)
+	data = append(data, make([]byte, 6)...)
+	data = append(data, 1, 1)
+	data = append(data, 0, 0, 0, 0, 0, 0, 0, depth+1)
+	data = append(data, make([]byte, 8)...)
+	data = append(data, 0, 0, 0, 0, 0, 0, 0, byte(tableOffset))
+
+	value, err := decodePlist(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < depth; i++ {
+		array, ok := value.([]interface{})
+		if !ok || len(array) != 2 {
+			t.Fatalf("level %d: got %#v, want an array of 2", i, value)
+		}
+		value = array[0]
+	}
+	if value != true {
+		t.Errorf("innermost value = %#v, want true", value)
+	}
+}
@@ -0,0 +1,70 @@
+package converter
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/jeromeandrewong/echo-vsc/internal/constants"
+)
+
+func isTmTheme(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".tmTheme")
+}
+
+// parseTmTheme reads a TextMate theme plist. The global settings entry (the one
+// without a scope) becomes VS Code editor colors and every scoped entry a token color.
+func parseTmTheme(contents []byte) (vscodeTheme, error) {
+	root, err := decodePlist(bytes.NewReader(contents))
+	if err != nil {
+		return vscodeTheme{}, fmt.Errorf("error parsing tmTheme: %v", err)
+	}
+
+	dict, ok := root.(map[string]interface{})
+	if !ok {
+		return vscodeTheme{}, fmt.Errorf("invalid tmTheme format: root is not a dict")
+	}
+
+	entries, ok := dict["settings"].([]interface{})
+	if !ok {
+		return vscodeTheme{}, fmt.Errorf("invalid tmTheme format: settings not found or not an array")
+	}
+
+	themeData := vscodeTheme{
+		Colors: make(map[string]interface{}),
+	}
+
+	for _, entry := range entries {
+		entryDict, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		settings, ok := entryDict["settings"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		scope, _ := entryDict["scope"].(string)
+		if scope == "" {
+			for tmKey, vscodeKey := range constants.VSCodeColorFromTmTheme {
+				if color, ok := settings[tmKey].(string); ok && color != "" {
+					themeData.Colors[vscodeKey] = color
+				}
+			}
+			continue
+		}
+
+		var rule tokenColor
+		rule.Name, _ = entryDict["name"].(string)
+		rule.Scope = splitScopes(scope)
+		rule.Settings.Foreground, _ = settings["foreground"].(string)
+		rule.Settings.Background, _ = settings["background"].(string)
+		rule.Settings.FontStyle, _ = settings["fontStyle"].(string)
+
+		themeData.TokenColors = append(themeData.TokenColors, rule)
+	}
+
+	return themeData, nil
+}
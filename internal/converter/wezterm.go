@@ -0,0 +1,103 @@
+package converter
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/jeromeandrewong/echo-vsc/pkg/utils"
+)
+
+type wezTermColor struct {
+	name string
+	keys []string
+}
+
+// wezterm tab bar tables and the VS Code keys each color is taken from, in order of preference
+var wezTermTabBarTables = []struct {
+	table  string
+	colors []wezTermColor
+}{
+	{"colors.tab_bar", []wezTermColor{
+		{"background", []string{"titleBar.activeBackground", "editorGroupHeader.tabsBackground"}},
+	}},
+	{"colors.tab_bar.active_tab", []wezTermColor{
+		{"bg_color", []string{"tab.activeBackground"}},
+		{"fg_color", []string{"tab.activeForeground", "titleBar.activeForeground"}},
+	}},
+	{"colors.tab_bar.inactive_tab", []wezTermColor{
+		{"bg_color", []string{"tab.inactiveBackground", "titleBar.inactiveBackground"}},
+		{"fg_color", []string{"tab.inactiveForeground", "titleBar.inactiveForeground"}},
+	}},
+	{"colors.tab_bar.inactive_tab_hover", []wezTermColor{
+		{"bg_color", []string{"tab.hoverBackground"}},
+		{"fg_color", []string{"tab.hoverForeground"}},
+	}},
+	{"colors.tab_bar.new_tab", []wezTermColor{
+		{"bg_color", []string{"titleBar.activeBackground", "editorGroupHeader.tabsBackground"}},
+		{"fg_color", []string{"titleBar.activeForeground"}},
+	}},
+}
+
+func getWezTermTOML(p palette) string {
+	var buffer bytes.Buffer
+
+	writeTOMLTable(&buffer, "colors", [][2]string{
+		{"foreground", utils.RGBAToHex(p.Colors["Foreground Color"])},
+		{"background", utils.RGBAToHex(p.Colors["Background Color"])},
+		{"cursor_bg", utils.RGBAToHex(p.Colors["Cursor Color"])},
+		{"cursor_fg", utils.RGBAToHex(p.Colors["Cursor Text Color"])},
+		{"cursor_border", utils.RGBAToHex(p.Colors["Cursor Color"])},
+		{"selection_fg", utils.RGBAToHex(p.Colors["Selected Text Color"])},
+		{"selection_bg", utils.RGBAToHex(p.Colors["Selection Color"])},
+	})
+	fmt.Fprintf(&buffer, "ansi = %s\n", tomlStringArray(ansiEntries(p, 0)))
+	fmt.Fprintf(&buffer, "brights = %s\n", tomlStringArray(ansiEntries(p, 8)))
+
+	for _, tabBar := range wezTermTabBarTables {
+		var entries [][2]string
+		for _, color := range tabBar.colors {
+			if hex, ok := sourceColor(p, color.keys); ok {
+				entries = append(entries, [2]string{color.name, hex})
+			}
+		}
+
+		// wezterm requires both colors for a tab, so only emit complete tables
+		if len(entries) == len(tabBar.colors) {
+			writeTOMLTable(&buffer, tabBar.table, entries)
+		}
+	}
+
+	writeTOMLTable(&buffer, "metadata", [][2]string{
+		{"name", p.Name},
+	})
+
+	return buffer.String()
+}
+
+// sourceColor returns the first of the given VS Code keys the theme defines, as #rrggbb
+func sourceColor(p palette, keys []string) (string, bool) {
+	for _, key := range keys {
+		val, ok := p.Source[key].(string)
+		if !ok {
+			continue
+		}
+
+		colorRGBA, err := utils.HexToRGBA(val)
+		if err != nil {
+			continue
+		}
+
+		return utils.RGBAToHex(utils.Composite(colorRGBA, p.Colors["Background Color"])), true
+	}
+
+	return "", false
+}
+
+func tomlStringArray(entries [][2]string) string {
+	values := make([]string, len(entries))
+	for i, entry := range entries {
+		values[i] = fmt.Sprintf("%q", entry[1])
+	}
+	return "[" + strings.Join(values, ", ") + "]"
+}
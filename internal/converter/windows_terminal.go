@@ -0,0 +1,69 @@
+package converter
+
+import (
+	"encoding/json"
+
+	"github.com/jeromeandrewong/echo-vsc/pkg/utils"
+)
+
+// single entry of the "schemes" array in Windows Terminal's settings.json
+type windowsTerminalScheme struct {
+	Name                string `json:"name"`
+	Background          string `json:"background"`
+	Foreground          string `json:"foreground"`
+	CursorColor         string `json:"cursorColor"`
+	SelectionBackground string `json:"selectionBackground"`
+	Black               string `json:"black"`
+	Red                 string `json:"red"`
+	Green               string `json:"green"`
+	Yellow              string `json:"yellow"`
+	Blue                string `json:"blue"`
+	Purple              string `json:"purple"`
+	Cyan                string `json:"cyan"`
+	White               string `json:"white"`
+	BrightBlack         string `json:"brightBlack"`
+	BrightRed           string `json:"brightRed"`
+	BrightGreen         string `json:"brightGreen"`
+	BrightYellow        string `json:"brightYellow"`
+	BrightBlue          string `json:"brightBlue"`
+	BrightPurple        string `json:"brightPurple"`
+	BrightCyan          string `json:"brightCyan"`
+	BrightWhite         string `json:"brightWhite"`
+}
+
+func getWindowsTerminalJSON(p palette) (string, error) {
+	hex := func(name string) string {
+		return utils.RGBAToHex(p.Colors[name])
+	}
+
+	scheme := windowsTerminalScheme{
+		Name:                p.Name,
+		Background:          hex("Background Color"),
+		Foreground:          hex("Foreground Color"),
+		CursorColor:         hex("Cursor Color"),
+		SelectionBackground: hex("Selection Color"),
+		Black:               hex("Ansi 0 Color"),
+		Red:                 hex("Ansi 1 Color"),
+		Green:               hex("Ansi 2 Color"),
+		Yellow:              hex("Ansi 3 Color"),
+		Blue:                hex("Ansi 4 Color"),
+		Purple:              hex("Ansi 5 Color"),
+		Cyan:                hex("Ansi 6 Color"),
+		White:               hex("Ansi 7 Color"),
+		BrightBlack:         hex("Ansi 8 Color"),
+		BrightRed:           hex("Ansi 9 Color"),
+		BrightGreen:         hex("Ansi 10 Color"),
+		BrightYellow:        hex("Ansi 11 Color"),
+		BrightBlue:          hex("Ansi 12 Color"),
+		BrightPurple:        hex("Ansi 13 Color"),
+		BrightCyan:          hex("Ansi 14 Color"),
+		BrightWhite:         hex("Ansi 15 Color"),
+	}
+
+	data, err := json.MarshalIndent(scheme, "", "    ")
+	if err != nil {
+		return "", err
+	}
+
+	return string(data) + "\n", nil
+}
@@ -0,0 +1,76 @@
+package converter
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jeromeandrewong/echo-vsc/pkg/utils"
+)
+
+func TestGetWindowsTerminalJSON(t *testing.T) {
+	colors := map[string]string{
+		"Background Color": "#1e1e1e",
+		"Foreground Color": "#d4d4d4",
+		"Cursor Color":     "#aeafad",
+		"Selection Color":  "#264f78",
+	}
+	for slot := 0; slot < 16; slot++ {
+		colors[ansiKey(slot)] = utils.RGBAToHex(utils.RGBA{Red: float64(slot) / 15, Alpha: 1})
+	}
+
+	p := palette{Name: "Dark Modern", Type: "dark", Colors: make(map[string]utils.RGBA)}
+	for name, hex := range colors {
+		color, err := utils.HexToRGBA(hex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.Colors[name] = color
+	}
+
+	output, err := getWindowsTerminalJSON(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var scheme map[string]string
+	if err := json.Unmarshal([]byte(output), &scheme); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, output)
+	}
+
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{"name", "Dark Modern"},
+		{"background", colors["Background Color"]},
+		{"foreground", colors["Foreground Color"]},
+		{"cursorColor", colors["Cursor Color"]},
+		{"selectionBackground", colors["Selection Color"]},
+		{"black", colors[ansiKey(0)]},
+		{"red", colors[ansiKey(1)]},
+		{"green", colors[ansiKey(2)]},
+		{"yellow", colors[ansiKey(3)]},
+		{"blue", colors[ansiKey(4)]},
+		{"purple", colors[ansiKey(5)]},
+		{"cyan", colors[ansiKey(6)]},
+		{"white", colors[ansiKey(7)]},
+		{"brightBlack", colors[ansiKey(8)]},
+		{"brightRed", colors[ansiKey(9)]},
+		{"brightGreen", colors[ansiKey(10)]},
+		{"brightYellow", colors[ansiKey(11)]},
+		{"brightBlue", colors[ansiKey(12)]},
+		{"brightPurple", colors[ansiKey(13)]},
+		{"brightCyan", colors[ansiKey(14)]},
+		{"brightWhite", colors[ansiKey(15)]},
+	}
+
+	for _, tt := range tests {
+		if got := scheme[tt.field]; got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.field, got, tt.want)
+		}
+	}
+
+	if len(scheme) != len(tests) {
+		t.Errorf("got %d fields, want %d", len(scheme), len(tests))
+	}
+}
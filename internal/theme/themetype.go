@@ -0,0 +1,26 @@
+package theme
+
+// NormalizeType maps the spellings themes use for their type, including
+// uiTheme values, onto light, dark, hc or hcLight, returning an empty string
+// when it is unknown
+func NormalizeType(themeType string) string {
+	switch themeType {
+	case "light", "vs":
+		return "light"
+	case "dark", "vs-dark":
+		return "dark"
+	case "hc", "hc-black", "hcDark", "hc-dark":
+		return "hc"
+	case "hcLight", "hc-light":
+		return "hcLight"
+	}
+	return ""
+}
+
+func IsLightType(themeType string) bool {
+	return themeType == "light" || themeType == "hcLight"
+}
+
+func IsHighContrastType(themeType string) bool {
+	return themeType == "hc" || themeType == "hcLight"
+}
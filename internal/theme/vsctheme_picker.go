@@ -1,14 +1,23 @@
 package theme
 
 import (
+	"path/filepath"
+
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
 type Theme struct {
-	Label string
-	Path  string
+	Label            string
+	Path             string
+	UITheme          string // vs, vs-dark, hc-black or hc-light
+	ExtensionID      string // publisher.name of the contributing extension
+	ExtensionVersion string
+	ExtensionName    string // displayName of the contributing extension
+	Editor           string // editor whose extensions folder the theme was found in
+	Archive          string // .vsix the theme was read from, Path is then relative to its root
+	ExtensionPath    string // root of the contributing extension, in the same filesystem as Path
 }
 
 type Model struct {
@@ -19,9 +28,44 @@ type Model struct {
 var docStyle = lipgloss.NewStyle().Margin(1, 2)
 
 func (t Theme) Title() string       { return t.Label }
-func (t Theme) Description() string { return t.Path }
 func (t Theme) FilterValue() string { return t.Label }
 
+func (t Theme) Description() string {
+	if t.Editor == "" {
+		return t.Location()
+	}
+	return t.Editor + " · " + t.Location()
+}
+
+// Location is the theme file's path for display, pointing inside the archive
+// for themes read from a .vsix
+func (t Theme) Location() string {
+	if t.Archive == "" {
+		return t.Path
+	}
+	return t.Archive + "!/" + t.Path
+}
+
+// RelativePath is the theme file's path inside its extension, the same for
+// an installed extension and its .vsix
+func (t Theme) RelativePath() string {
+	if t.ExtensionPath == "" {
+		return filepath.ToSlash(t.Path)
+	}
+
+	rel, err := filepath.Rel(t.ExtensionPath, t.Path)
+	if err != nil {
+		return filepath.ToSlash(t.Path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// ThemeType maps the contribution's uiTheme onto the VS Code theme type,
+// returning an empty string when it is unknown
+func (t Theme) ThemeType() string {
+	return NormalizeType(t.UITheme)
+}
+
 func New(themes []Theme) Model {
 	items := make([]list.Item, len(themes))
 	for i, theme := range themes {
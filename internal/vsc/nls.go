@@ -0,0 +1,94 @@
+package vsc
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+)
+
+// isNLSPlaceholder reports whether a package.json value like "%theme.dark.label%"
+// refers to a localized string
+func isNLSPlaceholder(value string) bool {
+	return len(value) > 2 && strings.HasPrefix(value, "%") && strings.HasSuffix(value, "%")
+}
+
+// localize substitutes an NLS placeholder, reporting false if it can't be resolved.
+// Values that aren't placeholders are returned as they are.
+func localize(value string, messages map[string]string) (string, bool) {
+	if !isNLSPlaceholder(value) {
+		return value, true
+	}
+
+	message, ok := messages[strings.Trim(value, "%")]
+	return message, ok && message != ""
+}
+
+// loadNLS reads the extension's package.nls.json with the translations for the
+// current locale, if the extension ships them, merged on top
+func loadNLS(fsys fs.FS, root string) map[string]string {
+	messages := make(map[string]string)
+	readNLSFile(fsys, path.Join(root, "package.nls.json"), messages)
+
+	// try the least specific locale first so e.g. zh-cn overrides zh
+	candidates := getLocaleCandidates()
+	for i := len(candidates) - 1; i >= 0; i-- {
+		readNLSFile(fsys, path.Join(root, "package.nls."+candidates[i]+".json"), messages)
+	}
+
+	return messages
+}
+
+func readNLSFile(fsys fs.FS, nlsPath string, messages map[string]string) {
+	contents, err := fs.ReadFile(fsys, nlsPath)
+	if err != nil {
+		return
+	}
+
+	var entries map[string]json.RawMessage
+	if err := json.Unmarshal(contents, &entries); err != nil {
+		return
+	}
+
+	// entries are either plain strings or {"message": ..., "comment": [...]}
+	for key, raw := range entries {
+		var message string
+		if err := json.Unmarshal(raw, &message); err != nil {
+			var withComment struct {
+				Message string `json:"message"`
+			}
+			if err := json.Unmarshal(raw, &withComment); err != nil {
+				continue
+			}
+			message = withComment.Message
+		}
+		messages[key] = message
+	}
+}
+
+// getLocaleCandidates turns the POSIX locale, e.g. pt_BR.UTF-8, into the
+// names VS Code uses for its nls files, most specific first: pt-br, pt
+func getLocaleCandidates() []string {
+	var locale string
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if locale = os.Getenv(env); locale != "" {
+			break
+		}
+	}
+
+	locale = strings.SplitN(locale, ".", 2)[0]
+	locale = strings.SplitN(locale, "@", 2)[0]
+	locale = strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+
+	if locale == "" || locale == "c" || locale == "posix" {
+		return nil
+	}
+
+	candidates := []string{locale}
+	if language, _, found := strings.Cut(locale, "-"); found {
+		candidates = append(candidates, language)
+	}
+
+	return candidates
+}
@@ -0,0 +1,157 @@
+package vsc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/jeromeandrewong/echo-vsc/pkg/utils"
+)
+
+// GetSettingsPath returns the location of the VS Code user settings.json,
+// e.g. ~/.config/Code/User/settings.json on Linux
+func GetSettingsPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "Code", "User", "settings.json"), nil
+}
+
+// GetColorCustomizations reads workbench.colorCustomizations from a settings
+// file and returns the overrides that apply to the given theme: global ones
+// first, then any "[Theme Name]" blocks matching its label on top, later
+// blocks winning like they do in VS Code.
+// A missing settings file is not an error.
+func GetColorCustomizations(settingsPath string, themeLabel string) (map[string]interface{}, error) {
+	contents, err := os.ReadFile(settingsPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading settings: %v", err)
+	}
+
+	customizations, err := readColorCustomizations(utils.RemoveCommentsAndTrailingCommas(contents))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing settings: %v", err)
+	}
+
+	overrides := make(map[string]interface{})
+	var scoped []map[string]interface{}
+
+	for _, customization := range customizations {
+		if !strings.HasPrefix(customization.Key, "[") {
+			overrides[customization.Key] = customization.Value
+			continue
+		}
+
+		block, ok := customization.Value.(map[string]interface{})
+		if ok && themeScopeMatches(customization.Key, themeLabel) {
+			scoped = append(scoped, block)
+		}
+	}
+
+	for _, block := range scoped {
+		for key, value := range block {
+			overrides[key] = value
+		}
+	}
+
+	return overrides, nil
+}
+
+// entry of workbench.colorCustomizations
+type colorCustomization struct {
+	Key   string
+	Value interface{}
+}
+
+// readColorCustomizations returns the entries of workbench.colorCustomizations
+// in the order they are written, which decides between overlapping blocks
+func readColorCustomizations(settings []byte) ([]colorCustomization, error) {
+	decoder := json.NewDecoder(bytes.NewReader(settings))
+	if err := expectDelim(decoder, '{'); err != nil {
+		return nil, err
+	}
+
+	var customizations []colorCustomization
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		if key != "workbench.colorCustomizations" {
+			var skipped json.RawMessage
+			if err := decoder.Decode(&skipped); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		// a repeated key replaces the earlier one, as with any JSON object
+		customizations, err = readOrderedObject(decoder)
+		if err != nil {
+			return nil, fmt.Errorf("workbench.colorCustomizations: %v", err)
+		}
+	}
+
+	return customizations, nil
+}
+
+// readOrderedObject reads the next object's entries in order, or none for null
+func readOrderedObject(decoder *json.Decoder) ([]colorCustomization, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if token == nil {
+		return nil, nil
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return nil, fmt.Errorf("expected an object, got %v", token)
+	}
+
+	var entries []colorCustomization
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+		entries = append(entries, colorCustomization{Key: key.(string), Value: value})
+	}
+
+	return entries, expectDelim(decoder, '}')
+}
+
+func expectDelim(decoder *json.Decoder, want json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != want {
+		return fmt.Errorf("expected %v, got %v", want, token)
+	}
+	return nil
+}
+
+// themeScopeMatches reports whether a key like "[One Dark Pro]" or
+// "[Monokai*][Solarized Dark]" applies to the theme; names may use * wildcards
+func themeScopeMatches(key string, themeLabel string) bool {
+	for _, name := range strings.Split(strings.Trim(key, "[]"), "][") {
+		if matched, _ := path.Match(name, themeLabel); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package vsc
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGetColorCustomizations(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings string
+		label    string
+		want     map[string]interface{}
+	}{
+		{
+			name: "global then scoped",
+			settings: `{
+				"workbench.colorCustomizations": {
+					"[My Dark]": {"editor.background": "#111111"},
+					"editor.background": "#000000",
+					"editor.foreground": "#ffffff"
+				}
+			}`,
+			label: "My Dark",
+			want:  map[string]interface{}{"editor.background": "#111111", "editor.foreground": "#ffffff"},
+		},
+		{
+			name: "other themes' blocks are ignored",
+			settings: `{
+				"workbench.colorCustomizations": {
+					"editor.background": "#000000",
+					"[Other]": {"editor.background": "#111111"}
+				}
+			}`,
+			label: "My Dark",
+			want:  map[string]interface{}{"editor.background": "#000000"},
+		},
+		{
+			name: "multi-scope key",
+			settings: `{
+				"workbench.colorCustomizations": {
+					"[Other][My Dark]": {"terminal.ansiRed": "#ff0000"}
+				}
+			}`,
+			label: "My Dark",
+			want:  map[string]interface{}{"terminal.ansiRed": "#ff0000"},
+		},
+		{
+			name: "wildcard",
+			settings: `{
+				"workbench.colorCustomizations": {
+					"[*Dark*]": {"terminal.ansiRed": "#ff0000"},
+					"[Light*]": {"terminal.ansiRed": "#00ff00"}
+				}
+			}`,
+			label: "My Dark Pro",
+			want:  map[string]interface{}{"terminal.ansiRed": "#ff0000"},
+		},
+		{
+			name: "exact block after wildcard wins",
+			settings: `{
+				"workbench.colorCustomizations": {
+					"[My*]": {"terminal.ansiGreen": "#00ff00", "terminal.ansiRed": "#ff0000"},
+					"[My Dark]": {"terminal.ansiGreen": "#0000ff"}
+				}
+			}`,
+			label: "My Dark",
+			want:  map[string]interface{}{"terminal.ansiGreen": "#0000ff", "terminal.ansiRed": "#ff0000"},
+		},
+		{
+			name: "wildcard block after exact wins",
+			settings: `{
+				"workbench.colorCustomizations": {
+					"[My Dark]": {"terminal.ansiGreen": "#0000ff"},
+					"[My*]": {"terminal.ansiGreen": "#00ff00"}
+				}
+			}`,
+			label: "My Dark",
+			want:  map[string]interface{}{"terminal.ansiGreen": "#00ff00"},
+		},
+		{
+			name: "comments, trailing commas and other settings",
+			settings: `{
+				// editor settings
+				"editor.fontSize": 14,
+				"workbench.colorTheme": "My Dark",
+				"workbench.colorCustomizations": {
+					"editor.background": "#000000", // darker
+				},
+				"files.exclude": {"**/.git": true},
+			}`,
+			label: "My Dark",
+			want:  map[string]interface{}{"editor.background": "#000000"},
+		},
+		{
+			name:     "no customizations",
+			settings: `{"editor.fontSize": 14}`,
+			label:    "My Dark",
+			want:     map[string]interface{}{},
+		},
+	}
+
+	for _, tt := range tests {
+		settingsPath := filepath.Join(t.TempDir(), "settings.json")
+		if err := os.WriteFile(settingsPath, []byte(tt.settings), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := GetColorCustomizations(settingsPath, tt.label)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGetColorCustomizationsMissingFile(t *testing.T) {
+	got, err := GetColorCustomizations(filepath.Join(t.TempDir(), "settings.json"), "My Dark")
+	if err != nil || got != nil {
+		t.Errorf("got %v, %v, want nil, nil", got, err)
+	}
+}
+
+func TestGetColorCustomizationsInvalid(t *testing.T) {
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	if err := os.WriteFile(settingsPath, []byte(`{"workbench.colorCustomizations": "#000000"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := GetColorCustomizations(settingsPath, "My Dark"); err == nil {
+		t.Error("got no error for a non-object colorCustomizations")
+	}
+}
@@ -1,13 +1,22 @@
 package vsc
 
 import (
+	"archive/zip"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/jeromeandrewong/echo-vsc/internal/constants"
 	log "github.com/jeromeandrewong/echo-vsc/internal/logger"
 	"github.com/jeromeandrewong/echo-vsc/internal/theme"
 )
@@ -40,6 +49,99 @@ func processExtensionWorker(
 	}
 
 }
+
+// extensions folder to scan and the editor it belongs to
+type ExtensionsDir struct {
+	Editor string
+	Path   string
+}
+
+// GetExtensionsDirs returns the extensions folders of VS Code and its forks
+// under the home directory, followed by any extra folders given by the user
+// and the built-in extensions of the VS Code install
+func GetExtensionsDirs(homeDir string, builtinDir string, extraDirs []string) []ExtensionsDir {
+	var dirs []ExtensionsDir
+	for _, editorDir := range constants.EditorExtensionDirs {
+		dirs = append(dirs, ExtensionsDir{
+			Editor: editorDir.Editor,
+			Path:   filepath.Join(homeDir, editorDir.Dir),
+		})
+	}
+
+	for _, dir := range extraDirs {
+		dirs = append(dirs, ExtensionsDir{Editor: "Custom", Path: dir})
+	}
+
+	if builtinDir == "" {
+		builtinDir = findBuiltinExtensionsDir(homeDir)
+	}
+	if builtinDir != "" {
+		dirs = append(dirs, ExtensionsDir{Editor: "VS Code (built-in)", Path: builtinDir})
+	}
+
+	return dirs
+}
+
+// findBuiltinExtensionsDir returns the first known install location of VS Code's
+// built-in extensions that exists on this machine, or "" if none does
+func findBuiltinExtensionsDir(homeDir string) string {
+	for _, dir := range constants.BuiltinExtensionDirs[runtime.GOOS] {
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(homeDir, dir)
+		}
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+	}
+	return ""
+}
+
+// GetAllThemes scans every extensions folder, skipping the ones that don't
+// exist. An extension installed in several editors is only listed once, from
+// the first folder it was found in, going by its id, version and theme files.
+func GetAllThemes(dirs []ExtensionsDir) ([]theme.Theme, error) {
+	var allThemes []theme.Theme
+	seen := make(map[string]bool)
+
+	for _, dir := range dirs {
+		info, err := os.Stat(dir.Path)
+		if os.IsNotExist(err) {
+			log.Debug("Skipping missing extensions folder", "editor", dir.Editor, "path", dir.Path)
+			continue
+		}
+
+		var themes []theme.Theme
+		if err == nil && !info.IsDir() && isVSIX(dir.Path) {
+			themes, err = GetVSIXThemes(dir.Path)
+		} else {
+			themes, err = GetVSCThemes(dir.Path)
+		}
+		if err != nil {
+			log.Warn("⚠️ Failed to read extensions folder", "editor", dir.Editor, "path", dir.Path, "error", err)
+			continue
+		}
+
+		sortThemes(themes)
+		for _, t := range themes {
+			// the label only tells apart contributions sharing one file
+			key := t.ExtensionID + "@" + t.ExtensionVersion + "/" + t.RelativePath() + "#" + t.Label
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			t.Editor = dir.Editor
+			allThemes = append(allThemes, t)
+		}
+	}
+
+	if len(allThemes) == 0 {
+		return nil, fmt.Errorf("no themes found in any extensions folder")
+	}
+
+	return allThemes, nil
+}
+
 func GetVSCThemes(vscDir string) ([]theme.Theme, error) {
 	startTime := time.Now()
 
@@ -48,10 +150,10 @@ func GetVSCThemes(vscDir string) ([]theme.Theme, error) {
 		return nil, fmt.Errorf("error reading VSC directory: %v", err)
 	}
 
-	// count actual directories to process
+	// count actual extensions to process, folders or .vsix archives
 	dirCount := 0
 	for _, ext := range extensions {
-		if ext.IsDir() {
+		if isExtension(ext) {
 			dirCount++
 		}
 	}
@@ -75,7 +177,7 @@ func GetVSCThemes(vscDir string) ([]theme.Theme, error) {
 	// send jobs to workers
 	jobCount := 0
 	for _, extension := range extensions {
-		if !extension.IsDir() {
+		if !isExtension(extension) {
 			continue
 		}
 		jobs <- ExtensionJob{
@@ -108,7 +210,7 @@ func GetVSCThemes(vscDir string) ([]theme.Theme, error) {
 			processedCount++
 
 			if result.Err != nil {
-				log.Error("Warning: %v", result.Err)
+				log.Warn("⚠️ Failed to read extension", "error", result.Err)
 				continue
 			}
 
@@ -123,24 +225,81 @@ func GetVSCThemes(vscDir string) ([]theme.Theme, error) {
 
 		// Check if we've processed all extensions
 		if processedCount == jobCount {
-			processingTime := time.Since(startTime)
-			fmt.Printf("GetVSCThemes processing time: %v\n", processingTime)
-			fmt.Printf("Total themes found: %d\n", len(allThemes))
+			log.Debug("Finished processing extensions",
+				"duration", time.Since(startTime),
+				"themes", len(allThemes))
 			return allThemes, nil
 		}
 	}
 }
 
+func isVSIX(name string) bool {
+	return strings.EqualFold(filepath.Ext(name), ".vsix")
+}
+
+func isExtension(entry os.DirEntry) bool {
+	return entry.IsDir() || isVSIX(entry.Name())
+}
+
 func getThemesFromExtension(vscDir string, extension os.DirEntry) ([]theme.Theme, error) {
 	extensionPath := filepath.Join(vscDir, extension.Name())
 
-	packageJSONPath := filepath.Join(extensionPath, "package.json")
-	packageJSON, err := os.ReadFile(packageJSONPath)
+	if !extension.IsDir() {
+		return GetVSIXThemes(extensionPath)
+	}
+
+	// folders like node_modules in the built-in extensions aren't extensions
+	if _, err := os.Stat(filepath.Join(extensionPath, "package.json")); os.IsNotExist(err) {
+		log.Debug("Skipping folder without package.json", "path", extensionPath)
+		return nil, nil
+	}
+
+	themes, err := readExtensionThemes(os.DirFS(extensionPath), ".")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", extensionPath, err)
+	}
+
+	for i := range themes {
+		themes[i].Path = filepath.Join(extensionPath, filepath.FromSlash(themes[i].Path))
+		themes[i].ExtensionPath = extensionPath
+	}
+
+	return themes, nil
+}
+
+// GetVSIXThemes lists the themes contributed by a .vsix archive without installing it
+func GetVSIXThemes(vsixPath string) ([]theme.Theme, error) {
+	archive, err := zip.OpenReader(vsixPath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening %s: %v", vsixPath, err)
+	}
+	defer archive.Close()
+
+	// the extension itself sits under extension/ next to the vsix manifest
+	themes, err := readExtensionThemes(archive, "extension")
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range themes {
+		themes[i].Archive = vsixPath
+	}
+
+	return themes, nil
+}
+
+// readExtensionThemes reads the theme contributions from the package.json of
+// the extension at root, returning theme paths in the same filesystem
+func readExtensionThemes(fsys fs.FS, root string) ([]theme.Theme, error) {
+	packageJSON, err := fs.ReadFile(fsys, path.Join(root, "package.json"))
 	if err != nil {
 		return nil, fmt.Errorf("error reading package.json: %v", err)
 	}
 
 	var packageData struct {
+		Name        string `json:"name"`
+		Publisher   string `json:"publisher"`
+		Version     string `json:"version"`
 		DisplayName string `json:"displayName"`
 		Contributes struct {
 			Themes []theme.Theme `json:"themes"`
@@ -151,16 +310,190 @@ func getThemesFromExtension(vscDir string, extension os.DirEntry) ([]theme.Theme
 		return nil, fmt.Errorf("error parsing package.json: %v", err)
 	}
 
+	extensionID := packageData.Name
+	if packageData.Publisher != "" {
+		extensionID = packageData.Publisher + "." + packageData.Name
+	}
+
+	var messages map[string]string
+	if hasNLSPlaceholders(packageData.DisplayName, packageData.Contributes.Themes) {
+		messages = loadNLS(fsys, root)
+	}
+
+	displayName, ok := localize(packageData.DisplayName, messages)
+	if !ok || displayName == "" {
+		displayName = packageData.Name
+	}
+
 	var themes []theme.Theme
 
 	for _, t := range packageData.Contributes.Themes {
-		themePath := filepath.Join(extensionPath, t.Path)
+		themePath := path.Join(root, t.Path)
+
+		label, ok := localize(t.Label, messages)
+		if !ok {
+			log.Debug("Unresolved theme label", "label", t.Label, "extension", extensionID)
+			// the theme file tells apart several unresolved themes of one extension
+			label = displayName + " (" + path.Base(t.Path) + ")"
+		}
 
 		themes = append(themes, theme.Theme{
-			Label: t.Label,
-			Path:  themePath,
+			Label:            label,
+			Path:             themePath,
+			UITheme:          t.UITheme,
+			ExtensionID:      extensionID,
+			ExtensionVersion: packageData.Version,
+			ExtensionName:    displayName,
+			ExtensionPath:    root,
 		})
 	}
 
 	return themes, nil
 }
+
+func hasNLSPlaceholders(displayName string, themes []theme.Theme) bool {
+	if isNLSPlaceholder(displayName) {
+		return true
+	}
+	for _, t := range themes {
+		if isNLSPlaceholder(t.Label) {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrAmbiguousTheme is returned by FindTheme when several extensions have a
+// theme with the label
+var ErrAmbiguousTheme = errors.New("theme is ambiguous")
+
+// FindTheme returns the theme whose label matches, ignoring case, optionally
+// only among the themes of one extension. When several extensions match it
+// fails instead of picking one; an extension found in several folders or
+// versions resolves to the first one GetAllThemes returned.
+func FindTheme(themes []theme.Theme, label string, extensionID string) (theme.Theme, error) {
+	var matches []theme.Theme
+	seen := make(map[string]bool)
+	for _, t := range themes {
+		if !strings.EqualFold(t.Label, label) {
+			continue
+		}
+		if extensionID != "" && !strings.EqualFold(t.ExtensionID, extensionID) {
+			continue
+		}
+		if !seen[t.ExtensionID] {
+			seen[t.ExtensionID] = true
+			matches = append(matches, t)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		if extensionID != "" {
+			return theme.Theme{}, fmt.Errorf("theme %q not found in extension %s", label, extensionID)
+		}
+		return theme.Theme{}, fmt.Errorf("theme %q not found", label)
+	case 1:
+		return matches[0], nil
+	}
+
+	extensionIDs := make([]string, len(matches))
+	for i, t := range matches {
+		extensionIDs[i] = t.ExtensionID
+	}
+	return theme.Theme{}, fmt.Errorf("%w: %q is in extensions %s", ErrAmbiguousTheme, label, strings.Join(extensionIDs, ", "))
+}
+
+// sortThemes orders the themes of one extensions folder, which the workers
+// return in whatever order they finish, newest extension version first
+func sortThemes(themes []theme.Theme) {
+	sort.SliceStable(themes, func(i, j int) bool {
+		a, b := themes[i], themes[j]
+		if a.ExtensionID != b.ExtensionID {
+			return a.ExtensionID < b.ExtensionID
+		}
+		if order := compareVersions(a.ExtensionVersion, b.ExtensionVersion); order != 0 {
+			return order > 0
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Label < b.Label
+	})
+}
+
+// compareVersions compares dotted versions like 1.10.0 and 1.9.2 part by
+// part, numerically where both parts are numbers
+func compareVersions(a, b string) int {
+	aParts := strings.FieldsFunc(a, isVersionSeparator)
+	bParts := strings.FieldsFunc(b, isVersionSeparator)
+
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		aNumber, aErr := strconv.Atoi(aParts[i])
+		bNumber, bErr := strconv.Atoi(bParts[i])
+
+		switch {
+		case aErr == nil && bErr == nil && aNumber != bNumber:
+			if aNumber < bNumber {
+				return -1
+			}
+			return 1
+		case (aErr != nil || bErr != nil) && aParts[i] != bParts[i]:
+			return strings.Compare(aParts[i], bParts[i])
+		}
+	}
+
+	return len(aParts) - len(bParts)
+}
+
+func isVersionSeparator(r rune) bool {
+	return r == '.' || r == '-' || r == '+'
+}
+
+// FindSibling returns the theme from the same extension with the opposite
+// light/dark type, e.g. GitHub Dark for GitHub Light. When there are several,
+// the one sharing the most words with the label and the same contrast level wins.
+func FindSibling(themes []theme.Theme, selected theme.Theme) (theme.Theme, bool) {
+	selectedType := selected.ThemeType()
+	if selectedType == "" {
+		return theme.Theme{}, false
+	}
+
+	var best theme.Theme
+	bestScore := -1
+	for _, t := range themes {
+		themeType := t.ThemeType()
+		if t.ExtensionID != selected.ExtensionID || t.Editor != selected.Editor || themeType == "" {
+			continue
+		}
+		if theme.IsLightType(themeType) == theme.IsLightType(selectedType) {
+			continue
+		}
+
+		score := sharedWords(t.Label, selected.Label)
+		if theme.IsHighContrastType(themeType) == theme.IsHighContrastType(selectedType) {
+			score += 10
+		}
+
+		if score > bestScore {
+			best, bestScore = t, score
+		}
+	}
+
+	return best, bestScore >= 0
+}
+
+func sharedWords(a, b string) int {
+	words := make(map[string]bool)
+	for _, word := range strings.Fields(strings.ToLower(a)) {
+		words[word] = true
+	}
+
+	shared := 0
+	for _, word := range strings.Fields(strings.ToLower(b)) {
+		if words[word] {
+			shared++
+		}
+	}
+	return shared
+}
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -22,11 +24,33 @@ func GetDownloadsFolder() (string, error) {
 	return filepath.Join(homeDir, "Downloads"), nil
 }
 
+// GetITermDynamicProfilesFolder returns the folder iTerm2 loads Dynamic Profiles from
+func GetITermDynamicProfilesFolder() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, "Library", "Application Support", "iTerm2", "DynamicProfiles"), nil
+}
+
+// HexToRGBA parses the CSS hex forms #rgb, #rgba, #rrggbb and #rrggbbaa
 func HexToRGBA(hex string) (RGBA, error) {
 	hex = strings.TrimPrefix(hex, "#")
 
-	if len(hex) == 3 {
-		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	switch len(hex) {
+	case 3, 4:
+		expanded := make([]byte, 0, len(hex)*2)
+		for i := 0; i < len(hex); i++ {
+			expanded = append(expanded, hex[i], hex[i])
+		}
+		hex = string(expanded)
+	case 6, 8:
+	default:
+		return RGBA{}, fmt.Errorf("invalid hex color %q", hex)
+	}
+
+	if len(hex) == 6 {
+		hex += "ff"
 	}
 
 	values, err := strconv.ParseUint(hex, 16, 32)
@@ -35,13 +59,130 @@ func HexToRGBA(hex string) (RGBA, error) {
 	}
 
 	return RGBA{
-		Red:   float64((values>>16)&255) / 255.0,
-		Green: float64((values>>8)&255) / 255.0,
-		Blue:  float64(values&255) / 255.0,
-		Alpha: 1.0,
+		Red:   float64((values>>24)&255) / 255.0,
+		Green: float64((values>>16)&255) / 255.0,
+		Blue:  float64((values>>8)&255) / 255.0,
+		Alpha: float64(values&255) / 255.0,
 	}, nil
 }
 
+func RGBAToHex(color RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x",
+		uint8(math.Round(color.Red*255)),
+		uint8(math.Round(color.Green*255)),
+		uint8(math.Round(color.Blue*255)))
+}
+
+// Luminance returns the WCAG relative luminance of a color, from 0 (black) to 1 (white)
+func Luminance(color RGBA) float64 {
+	channel := func(c float64) float64 {
+		if c <= 0.03928 {
+			return c / 12.92
+		}
+		return math.Pow((c+0.055)/1.055, 2.4)
+	}
+
+	return 0.2126*channel(color.Red) + 0.7152*channel(color.Green) + 0.0722*channel(color.Blue)
+}
+
+// ContrastRatio returns the WCAG contrast ratio between two colors, from 1 to 21
+func ContrastRatio(a, b RGBA) float64 {
+	lighter, darker := Luminance(a), Luminance(b)
+	if darker > lighter {
+		lighter, darker = darker, lighter
+	}
+	return (lighter + 0.05) / (darker + 0.05)
+}
+
+// IsLight reports whether black text reads better on the color than white,
+// which happens from a luminance of about 0.18 since luminance isn't perceptual
+func IsLight(color RGBA) bool {
+	return ContrastRatio(color, RGBA{Alpha: 1}) > ContrastRatio(color, RGBA{Red: 1, Green: 1, Blue: 1, Alpha: 1})
+}
+
+// RGBAToHSL returns hue in degrees [0, 360) and saturation and lightness in [0, 1]
+func RGBAToHSL(color RGBA) (float64, float64, float64) {
+	max := math.Max(color.Red, math.Max(color.Green, color.Blue))
+	min := math.Min(color.Red, math.Min(color.Green, color.Blue))
+	lightness := (max + min) / 2
+
+	if max == min {
+		return 0, 0, lightness
+	}
+
+	delta := max - min
+	saturation := delta / (1 - math.Abs(2*lightness-1))
+
+	var hue float64
+	switch max {
+	case color.Red:
+		hue = math.Mod((color.Green-color.Blue)/delta, 6)
+	case color.Green:
+		hue = (color.Blue-color.Red)/delta + 2
+	default:
+		hue = (color.Red-color.Green)/delta + 4
+	}
+
+	hue *= 60
+	if hue < 0 {
+		hue += 360
+	}
+
+	return hue, saturation, lightness
+}
+
+func HSLToRGBA(hue, saturation, lightness, alpha float64) RGBA {
+	chroma := (1 - math.Abs(2*lightness-1)) * saturation
+	x := chroma * (1 - math.Abs(math.Mod(hue/60, 2)-1))
+	m := lightness - chroma/2
+
+	var r, g, b float64
+	switch {
+	case hue < 60:
+		r, g, b = chroma, x, 0
+	case hue < 120:
+		r, g, b = x, chroma, 0
+	case hue < 180:
+		r, g, b = 0, chroma, x
+	case hue < 240:
+		r, g, b = 0, x, chroma
+	case hue < 300:
+		r, g, b = x, 0, chroma
+	default:
+		r, g, b = chroma, 0, x
+	}
+
+	return RGBA{Red: r + m, Green: g + m, Blue: b + m, Alpha: alpha}
+}
+
+// Composite flattens a translucent color onto an opaque background
+func Composite(color RGBA, background RGBA) RGBA {
+	return RGBA{
+		Red:   color.Red*color.Alpha + background.Red*(1-color.Alpha),
+		Green: color.Green*color.Alpha + background.Green*(1-color.Alpha),
+		Blue:  color.Blue*color.Alpha + background.Blue*(1-color.Alpha),
+		Alpha: 1.0,
+	}
+}
+
+// Lighten shifts the lightness of a color by amount, keeping its hue;
+// a negative amount darkens it
+func Lighten(color RGBA, amount float64) RGBA {
+	hue, saturation, lightness := RGBAToHSL(color)
+	lightness = math.Max(0, math.Min(1, lightness+amount))
+	return HSLToRGBA(hue, saturation, lightness, color.Alpha)
+}
+
+// Mix blends from a towards b, weight 0 returning a and 1 returning b
+func Mix(a, b RGBA, weight float64) RGBA {
+	return RGBA{
+		Red:   a.Red + (b.Red-a.Red)*weight,
+		Green: a.Green + (b.Green-a.Green)*weight,
+		Blue:  a.Blue + (b.Blue-a.Blue)*weight,
+		Alpha: a.Alpha + (b.Alpha-a.Alpha)*weight,
+	}
+}
+
 func RemoveCommentsAndTrailingCommas(data []byte) []byte {
 	var result []byte
 	inString := false
@@ -84,11 +225,26 @@ func RemoveCommentsAndTrailingCommas(data []byte) []byte {
 			inBlockComment = true
 			i++
 		case data[i] == ',' && i < len(data)-1:
-			// check if the next non-whitespace character is a closing bracket or brace
+			// check if the next non-whitespace character is a closing bracket or brace,
+			// looking past comments like in `"key": 1, // note`
 			for j := i + 1; j < len(data); j++ {
 				if data[j] == ' ' || data[j] == '\t' || data[j] == '\n' || data[j] == '\r' {
 					continue
 				}
+				if j < len(data)-1 && data[j] == '/' && data[j+1] == '/' {
+					for j < len(data)-1 && data[j+1] != '\n' {
+						j++
+					}
+					continue
+				}
+				if j < len(data)-1 && data[j] == '/' && data[j+1] == '*' {
+					j += 2
+					for j < len(data)-1 && !(data[j] == '*' && data[j+1] == '/') {
+						j++
+					}
+					j++
+					continue
+				}
 				if data[j] == '}' || data[j] == ']' {
 					// skip this comma
 					i = j - 1
@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func channel(value uint8) float64 {
+	return float64(value) / 255
+}
+
+func sameColor(a, b RGBA) bool {
+	const epsilon = 1e-9
+	return math.Abs(a.Red-b.Red) < epsilon &&
+		math.Abs(a.Green-b.Green) < epsilon &&
+		math.Abs(a.Blue-b.Blue) < epsilon &&
+		math.Abs(a.Alpha-b.Alpha) < epsilon
+}
+
+func TestHexToRGBA(t *testing.T) {
+	tests := []struct {
+		hex     string
+		want    RGBA
+		wantErr bool
+	}{
+		{hex: "#abc", want: RGBA{channel(0xaa), channel(0xbb), channel(0xcc), 1}},
+		{hex: "#abcd", want: RGBA{channel(0xaa), channel(0xbb), channel(0xcc), channel(0xdd)}},
+		{hex: "#aabbcc", want: RGBA{channel(0xaa), channel(0xbb), channel(0xcc), 1}},
+		{hex: "#aabbcc80", want: RGBA{channel(0xaa), channel(0xbb), channel(0xcc), channel(0x80)}},
+		{hex: "aabbcc", want: RGBA{channel(0xaa), channel(0xbb), channel(0xcc), 1}},
+		{hex: "", wantErr: true},
+		{hex: "#12345", wantErr: true},
+		{hex: "#gggggg", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := HexToRGBA(tt.hex)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("HexToRGBA(%q) = %+v, want an error", tt.hex, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("HexToRGBA(%q) returned error: %v", tt.hex, err)
+			continue
+		}
+		if !sameColor(got, tt.want) {
+			t.Errorf("HexToRGBA(%q) = %+v, want %+v", tt.hex, got, tt.want)
+		}
+	}
+}
+
+func TestComposite(t *testing.T) {
+	white := RGBA{1, 1, 1, 1}
+	black := RGBA{0, 0, 0, 1}
+
+	tests := []struct {
+		name       string
+		color      RGBA
+		background RGBA
+		want       RGBA
+	}{
+		{"opaque", RGBA{1, 0, 0, 1}, white, RGBA{1, 0, 0, 1}},
+		{"transparent", RGBA{1, 0, 0, 0}, black, black},
+		{"half", RGBA{1, 1, 1, 0.5}, black, RGBA{0.5, 0.5, 0.5, 1}},
+		{"quarter", RGBA{0, 0, 1, 0.25}, white, RGBA{0.75, 0.75, 1, 1}},
+	}
+
+	for _, tt := range tests {
+		if got := Composite(tt.color, tt.background); !sameColor(got, tt.want) {
+			t.Errorf("%s: Composite(%+v, %+v) = %+v, want %+v", tt.name, tt.color, tt.background, got, tt.want)
+		}
+	}
+}
+
+func TestRemoveCommentsAndTrailingCommas(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  interface{}
+	}{
+		{"plain", `{"a": 1}`, map[string]interface{}{"a": 1.0}},
+		{"trailing comma", `{"a": [1, 2,], }`, map[string]interface{}{"a": []interface{}{1.0, 2.0}}},
+		{"line comment", "{\"a\": 1 // one\n}", map[string]interface{}{"a": 1.0}},
+		{"block comment", `{/* a */ "a": 1}`, map[string]interface{}{"a": 1.0}},
+		{"trailing comma before line comment", "{\"a\": 1, // one\n}", map[string]interface{}{"a": 1.0}},
+		{"trailing comma before block comment", `{"a": [1, /* more */], }`, map[string]interface{}{"a": []interface{}{1.0}}},
+		{"comment markers in strings", `{"a": "http://x/*y*/",}`, map[string]interface{}{"a": "http://x/*y*/"}},
+	}
+
+	for _, tt := range tests {
+		var got interface{}
+		cleaned := RemoveCommentsAndTrailingCommas([]byte(tt.input))
+		if err := json.Unmarshal(cleaned, &got); err != nil {
+			t.Errorf("%s: %v in %s", tt.name, err, cleaned)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsLight(t *testing.T) {
+	tests := []struct {
+		hex  string
+		want bool
+	}{
+		{"#ffffff", true},
+		{"#bbbbbb", true}, // luminance just under 0.5, yet clearly light
+		{"#808080", true},
+		{"#fdf6e3", true},
+		{"#6e6e6e", false},
+		{"#282c34", false},
+		{"#000000", false},
+	}
+
+	for _, tt := range tests {
+		color, err := HexToRGBA(tt.hex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := IsLight(color); got != tt.want {
+			t.Errorf("IsLight(%s) = %v, want %v (luminance %.3f)", tt.hex, got, tt.want, Luminance(color))
+		}
+	}
+}